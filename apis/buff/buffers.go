@@ -21,6 +21,9 @@ type Buffer interface {
 
 	SkpLen(delta int32)
 
+	// the first error met while pulling, pulls after it return zero values
+	Err() error
+
 	// pull
 	PullBit() bool
 
@@ -0,0 +1,56 @@
+package chns
+
+import (
+	"github.com/golangmc/minecraft-server/apis/buff"
+	"github.com/golangmc/minecraft-server/apis/ents"
+)
+
+// Message is the payload of a plugin message on its channel
+type Message interface {
+	Chan() string
+
+	buff.BufferPush
+	buff.BufferPull
+}
+
+type Handler func(player ents.Player, message Message)
+
+// Channels routes plugin messages between plugins and players
+type Channels interface {
+	// Register handles messages players send on the channel, create decodes them as a typed message, they are Raw without it
+	Register(channel string, create func() Message, handler Handler)
+
+	Unregister(channel string)
+
+	// Registered lists the channels plugins handle, players are told about them when they join
+	Registered() []string
+
+	// Send writes the message to the player
+	Send(player ents.Player, message Message)
+
+	// Announced lists the channels the player's client registered through minecraft:register
+	Announced(player ents.Player) []string
+
+	Listens(player ents.Player, channel string) bool
+}
+
+// Raw is a message sent as is, the data takes up the rest of the packet
+type Raw struct {
+	Channel string
+	Data    []byte
+}
+
+func (r *Raw) Chan() string {
+	return r.Channel
+}
+
+func (r *Raw) Push(writer buff.Buffer) {
+	writer.PushUAS(r.Data, false)
+}
+
+func (r *Raw) Pull(reader buff.Buffer) {
+	r.Data = make([]byte, reader.Len()-reader.InI())
+	copy(r.Data, reader.UAS()[reader.InI():])
+
+	reader.SkpLen(int32(len(r.Data)))
+}
@@ -19,6 +19,9 @@ type Message struct {
 	Text  string          `json:"text"`
 	Color *chat.ChatColor `json:"color,string,omitempty"`
 
+	Translate string     `json:"translate,omitempty"`
+	With      []*Message `json:"with,omitempty"`
+
 	Bold          *bool `json:"bold,boolean,omitempty"`
 	Italic        *bool `json:"italic,boolean,omitempty"`
 	Underlined    *bool `json:"underlined,boolean,omitempty"`
@@ -36,6 +39,19 @@ func New(text string) *Message {
 	}
 }
 
+// creates a message the client translates with its own language, such as "multiplayer.disconnect.outdated_client"
+func NewTranslate(key string, with ...string) *Message {
+	message := &Message{
+		Translate: key,
+	}
+
+	for _, text := range with {
+		message.With = append(message.With, New(text))
+	}
+
+	return message
+}
+
 func (c *Message) SetColor(code chat.ChatColor) *Message {
 	c.Color = &code
 	return c
@@ -118,6 +134,36 @@ func (c *Message) AsJson() string {
 	}
 }
 
+// parses a message as sent by the server, plain json strings become a text message
+func OfJson(text string) *Message {
+	message := &Message{}
+
+	if err := json.Unmarshal([]byte(text), message); err == nil {
+		return message
+	}
+
+	var plain string
+	if err := json.Unmarshal([]byte(text), &plain); err == nil {
+		return New(plain)
+	}
+
+	return New(text)
+}
+
+// the client reads text before translate, so text is left out of translated messages
+func (c *Message) MarshalJSON() ([]byte, error) {
+	type message Message
+
+	if c.Translate == "" {
+		return json.Marshal((*message)(c))
+	}
+
+	return json.Marshal(&struct {
+		Text *string `json:"text,omitempty"`
+		*message
+	}{message: (*message)(c)})
+}
+
 func (c *Message) AsText() string {
 	builder := strings.Builder{}
 
@@ -11,6 +11,15 @@ const (
 
 var CurrentProtocol = MC1_15_2
 
+// every version the server is able to speak, oldest first
+//
+// 1.12.2 is left out, its chunks need the block ids from before the flattening
+var SupportedVersions = []MinecraftVersion{
+	MC1_13_2,
+	MC1_14_4,
+	MC1_15_2,
+}
+
 var protocolVersion = map[MinecraftVersion]int{
 	MC1_12_2: 340,
 	MC1_13_2: 404,
@@ -22,6 +31,17 @@ func (m MinecraftVersion) Protocol() int {
 	return protocolVersion[m]
 }
 
+// VersionOfProtocol finds the supported version using the given protocol number
+func VersionOfProtocol(protocol int) (version MinecraftVersion, ok bool) {
+	for _, version := range SupportedVersions {
+		if version.Protocol() == protocol {
+			return version, true
+		}
+	}
+
+	return CurrentProtocol, false
+}
+
 func (m MinecraftVersion) String() string {
 	switch m {
 	case MC1_12_2:
@@ -16,6 +16,10 @@ type Player interface {
 
 	GetProfile() *game.Profile
 
+	// the smoothed round trip time of keep alives, in milliseconds
+	GetPing() int32
+	SetPing(ping int32)
+
 	GetLocation() data.Location
 	SetLocation(loc data.Location)
 }
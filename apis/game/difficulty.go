@@ -30,17 +30,17 @@ func ValueOfDifficulty(d Difficulty) byte {
 	return byte(d)
 }
 
-func DifficultyValueOf(id byte) Difficulty {
+func DifficultyValueOf(id byte) (Difficulty, error) {
 	switch id {
 	case 0:
-		return PEACEFUL
+		return PEACEFUL, nil
 	case 1:
-		return EASY
+		return EASY, nil
 	case 2:
-		return NORMAL
+		return NORMAL, nil
 	case 3:
-		return HARD
+		return HARD, nil
 	default:
-		panic(fmt.Errorf("no difficulty for id %d", id))
+		return PEACEFUL, fmt.Errorf("no difficulty for id %d", id)
 	}
 }
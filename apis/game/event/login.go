@@ -0,0 +1,36 @@
+package event
+
+import (
+	"time"
+
+	"github.com/golangmc/minecraft-server/apis/game"
+)
+
+// PlayerLoginEvent is published before a player joins, the login waits for every query its handlers send
+type PlayerLoginEvent struct {
+	Profile game.Profile
+
+	Login
+}
+
+// Login is a player that is still logging in
+type Login interface {
+	// Query sends a login plugin message on the channel, the callback runs once the client answers or the timeout passes
+	//
+	// the login continues after the last callback returns, so callbacks may query again or disconnect the player
+	Query(channel string, data []byte, timeout time.Duration, callback func(response LoginResponse))
+
+	// Disconnect refuses the login
+	Disconnect(reason string)
+}
+
+type LoginResponse struct {
+	Channel string
+
+	// false if the client does not know the channel, clients before 1.13 never do
+	Understood bool
+	// true if the client did not answer in time
+	TimedOut bool
+
+	Data []byte
+}
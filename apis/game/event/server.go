@@ -0,0 +1,21 @@
+package event
+
+import (
+	"net"
+
+	"github.com/golangmc/minecraft-server/apis/game"
+)
+
+// ServerListPingEvent is published for every server list ping, cancelled pings are not answered
+type ServerListPingEvent struct {
+	Address  net.Addr
+	Protocol int
+
+	Motd    string
+	Online  int
+	Max     int
+	Sample  []game.Profile
+	Favicon string // a data:image/png;base64 uri, empty for none
+
+	Cancellable
+}
@@ -25,3 +25,14 @@ var typeToName = map[LevelType]string{
 func (l LevelType) String() string {
 	return typeToName[l]
 }
+
+// returns the level type with the name, and DEFAULT for unknown names
+func LevelTypeValueOf(name string) LevelType {
+	for level, text := range typeToName {
+		if text == name {
+			return level
+		}
+	}
+
+	return DEFAULT
+}
@@ -3,7 +3,7 @@ package rand
 import "crypto/rand"
 
 func RandomByteArray(len int) []byte {
-	array := make([]byte, 4)
+	array := make([]byte, len)
 	_, _ = rand.Read(array)
 
 	return array
@@ -4,6 +4,7 @@ import (
 	"github.com/golangmc/minecraft-server/apis/game/level"
 	"sync"
 
+	"github.com/golangmc/minecraft-server/apis/chns"
 	"github.com/golangmc/minecraft-server/apis/cmds"
 	"github.com/golangmc/minecraft-server/apis/ents"
 	"github.com/golangmc/minecraft-server/apis/logs"
@@ -22,6 +23,8 @@ type Server interface {
 
 	Command() *cmds.CommandManager
 
+	Channels() chns.Channels
+
 	Tasking() *task.Tasking
 
 	Watcher() util.Watcher
@@ -33,6 +33,7 @@ type Tasking struct {
 	next uint64
 	done bool
 	kill chan bool
+	stop chan struct{} // closed once tick returned and dropped the tasks, they are only touched by it until then
 }
 
 func NewTasking(mpt int64) *Tasking {
@@ -48,10 +49,12 @@ func NewTasking(mpt int64) *Tasking {
 func (t *Tasking) Load() {
 	t.done = false
 	t.kill = make(chan bool, 1)
+	t.stop = make(chan struct{})
 
 	go t.tick()
 }
 
+// Kill stops the ticking without waiting for it, so a task may kill its own tasking
 func (t *Tasking) Kill() {
 	if t.done {
 		return
@@ -60,19 +63,6 @@ func (t *Tasking) Kill() {
 	t.done = true
 	t.kill <- true
 
-	for k := range t.ticks {
-		delete(t.ticks, k)
-	}
-
-	for k := range t.queue {
-		delete(t.queue, k)
-	}
-
-	for k, v := range t.tasks {
-		delete(t.tasks, k)
-		v.Cancel()
-	}
-
 	close(t.kill)
 }
 
@@ -80,6 +70,9 @@ func (t *Tasking) tick() {
 	tick := time.NewTicker(time.Millisecond)
 	defer tick.Stop()
 
+	defer close(t.stop)
+	defer t.clear()
+
 	for {
 		select {
 		case <-t.kill:
@@ -91,6 +84,22 @@ func (t *Tasking) tick() {
 	}
 }
 
+// clear cancels and drops every task, only tick calls it so nothing runs them meanwhile
+func (t *Tasking) clear() {
+	for k := range t.ticks {
+		delete(t.ticks, k)
+	}
+
+	for k := range t.queue {
+		delete(t.queue, k)
+	}
+
+	for k, v := range t.tasks {
+		delete(t.tasks, k)
+		v.Cancel()
+	}
+}
+
 func (t *Tasking) tickTasks(curr time.Time) {
 	unix := curr.UnixNano() / 1e6
 
@@ -3,6 +3,7 @@ package task
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestTasker_Load(t *testing.T) {
@@ -25,3 +26,31 @@ func printCurrentTask(_ *Task) {
 		panic("hi")
 	}
 }
+
+func TestTasking_KillFromTask(t *testing.T) {
+	tasker := NewTasking(1_000 / 20)
+	killed := make(chan struct{})
+
+	tasker.After(1, func(task *Task) {
+		tasker.Kill()
+		close(killed)
+	})
+
+	tasker.Load()
+
+	select {
+	case <-killed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("kill blocked the task calling it")
+	}
+
+	select {
+	case <-tasker.stop:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tasking kept ticking after kill")
+	}
+
+	if len(tasker.tasks) != 0 {
+		t.Fatalf("expected the tasks to be dropped, %d are left", len(tasker.tasks))
+	}
+}
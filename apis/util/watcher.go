@@ -39,13 +39,21 @@ type watcher struct {
 }
 
 func (w *watcher) Has(topic string) bool {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
 	handlers, contains := w.topics[topic]
 
 	return contains && len(handlers) > 0
 }
 
 func (w *watcher) Pub(topic string, args ...interface{}) {
-	if handlers, contains := w.topics[topic]; contains && len(handlers) > 0 {
+	// handlers are called without the lock, they may sub and unsub themselves
+	w.locker.Lock()
+	handlers, contains := w.topics[topic]
+	w.locker.Unlock()
+
+	if contains && len(handlers) > 0 {
 
 		callArgs := make([]reflect.Value, 0)
 		for _, arg := range args {
@@ -118,14 +126,22 @@ type handler struct {
 }
 
 func (h *handler) UnSub() {
+	h.watch.locker.Lock()
+	defer h.watch.locker.Unlock()
+
 	handlers := h.watch.topics[h.topic]
 	if handlers == nil {
 		return
 	}
 
-	for i, elem := range handlers {
-		if elem == h {
-			h.watch.topics[h.topic] = append(h.watch.topics[h.topic][:i], h.watch.topics[h.topic][i+1:]...)
+	// a new slice, publishers may still be ranging over the old one
+	remaining := make([]*handler, 0, len(handlers))
+
+	for _, elem := range handlers {
+		if elem != h {
+			remaining = append(remaining, elem)
 		}
 	}
+
+	h.watch.topics[h.topic] = remaining
 }
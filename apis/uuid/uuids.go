@@ -28,6 +28,11 @@ func FromString(text string) UUID {
 	return result
 }
 
+// Parse decodes a uuid with or without dashes, returning an error instead of panicking
+func Parse(text string) (UUID, error) {
+	return uuid.FromString(text)
+}
+
 func TextToUUID(text string) UUID {
 	bytes := md5.Sum([]byte(text))
 	bytes[6] = (bytes[6] & 0x0f) | 0x30
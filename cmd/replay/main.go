@@ -0,0 +1,54 @@
+// replay feeds a packet capture into the packet handlers of a server that is not listening,
+// printing every packet the server sends in return
+//
+//	go run ./cmd/replay -file capture.jsonl
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/golangmc/minecraft-server/impl"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conf"
+)
+
+func main() {
+	file := flag.String("file", "", "the capture file to replay")
+	online := flag.Bool("online", conf.DefaultServerConfig.OnlineMode, "whether the captured server was in online mode")
+	settle := flag.Duration("wait", time.Second, "how long to wait for handlers still running after the last packet")
+
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	capture, err := os.Open(*file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	defer capture.Close()
+
+	config := conf.DefaultServerConfig
+	config.OnlineMode = *online
+
+	err = impl.Replay(&config, capture, func(conn base.Connection, packet base.PacketO) {
+		fields, _ := json.Marshal(packet)
+		fmt.Printf("%v %v %v %s\n", conn.Address(), conn.GetState(), reflect.TypeOf(packet).Elem().Name(), fields)
+	})
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	time.Sleep(*settle)
+}
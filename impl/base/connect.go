@@ -1,12 +1,27 @@
 package base
 
-import "net"
+import (
+	"net"
+
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/game"
+)
 
 type Connection interface {
 	Address() net.Addr
+	// replaces the address with the one forwarded by a proxy
+	SetAddress(address net.Addr)
+
+	// the profile forwarded by a proxy, nil if there is none
+	Forwarded() *game.Profile
+	SetForwarded(profile *game.Profile)
 
 	GetState() PacketState
-	SetState(state PacketState)
+	// moves the connection to the state, an error if the current state can't move there
+	SetState(state PacketState) error
+
+	GetVersion() data.MinecraftVersion
+	SetVersion(version data.MinecraftVersion)
 
 	Encrypt(data []byte) (output []byte)
 	Decrypt(data []byte) (output []byte)
@@ -18,13 +33,19 @@ type Connection interface {
 	CertifyValues(name string)
 	CertifyUpdate(secret []byte)
 
+	CompactUpdate(size int32)
+
 	Deflate(data []byte) (output []byte)
-	Inflate(data []byte) (output []byte)
+	Inflate(data []byte) (output []byte, err error)
 
 	Pull(data []byte) (len int, err error)
 	Push(data []byte) (len int, err error)
 
+	// stop the connection, packets already sent are still written to the client
 	Stop() (err error)
 
+	// whether the connection was stopped, packets received after that are not handled
+	Closed() bool
+
 	SendPacket(packet PacketO)
 }
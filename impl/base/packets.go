@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/golangmc/minecraft-server/apis/buff"
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/data/msgs"
 	"github.com/golangmc/minecraft-server/apis/util"
 )
 
@@ -20,18 +22,18 @@ func ValueOfPacketState(s PacketState) int {
 	return int(s)
 }
 
-func PacketStateValueOf(s int) PacketState {
+func PacketStateValueOf(s int) (PacketState, error) {
 	switch s {
 	case 0:
-		return SHAKE
+		return SHAKE, nil
 	case 1:
-		return STATUS
+		return STATUS, nil
 	case 2:
-		return LOGIN
+		return LOGIN, nil
 	case 3:
-		return PLAY
+		return PLAY, nil
 	default:
-		panic(fmt.Errorf("no state for value: %d", s))
+		return SHAKE, fmt.Errorf("no state for value: %d", s)
 	}
 }
 
@@ -50,19 +52,66 @@ func (state PacketState) String() string {
 	}
 }
 
-func (state PacketState) Next() PacketState {
+func (state PacketState) MarshalText() ([]byte, error) {
+	return []byte(state.String()), nil
+}
+
+func (state *PacketState) UnmarshalText(text []byte) error {
+	for _, value := range []PacketState{SHAKE, STATUS, LOGIN, PLAY} {
+		if value.String() == string(text) {
+			*state = value
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no state for name: %s", text)
+}
+
+// CanMoveTo tells if a connection in this state may move to next, a handshake leads to status or login and login to play
+func (state PacketState) CanMoveTo(next PacketState) bool {
 	switch state {
 	case SHAKE:
-		return STATUS
-	case STATUS:
-		return LOGIN
+		return next == STATUS || next == LOGIN
 	case LOGIN:
-		return PLAY
-	case PLAY:
-		return SHAKE
+		return next == PLAY
 	default:
-		panic(fmt.Errorf("no state for value: %d", state))
+		return false
+	}
+}
+
+type PacketDirection int
+
+const (
+	// packets sent by the client to the server
+	SERVERBOUND PacketDirection = iota
+	// packets sent by the server to the client
+	CLIENTBOUND
+)
+
+func (direction PacketDirection) String() string {
+	switch direction {
+	case SERVERBOUND:
+		return "Serverbound"
+	case CLIENTBOUND:
+		return "Clientbound"
+	default:
+		panic(fmt.Errorf("no direction for value: %d", direction))
+	}
+}
+
+func (direction PacketDirection) MarshalText() ([]byte, error) {
+	return []byte(direction.String()), nil
+}
+
+func (direction *PacketDirection) UnmarshalText(text []byte) error {
+	for _, value := range []PacketDirection{SERVERBOUND, CLIENTBOUND} {
+		if value.String() == string(text) {
+			*direction = value
+			return nil
+		}
 	}
+
+	return fmt.Errorf("no direction for name: %s", text)
 }
 
 type Packet interface {
@@ -74,7 +123,7 @@ type PacketI interface {
 	Packet
 
 	// decode the server_data from the reader into this packet
-	Pull(reader buff.Buffer, conn Connection)
+	Pull(reader buff.Buffer, conn Connection) error
 }
 
 type PacketO interface {
@@ -87,9 +136,15 @@ type PacketO interface {
 type Packets interface {
 	util.Watcher
 
-	/*GetPacketM(uuid int32, state PacketState) (pid int32, cont bool)*/
+	// maps the uuid of an outgoing packet to its id in the version, cont is false if the version lacks the packet
+	GetPacketM(uuid int32, state PacketState, version data.MinecraftVersion) (pid int32, cont bool)
+
+	// creates the incoming packet with the id it has in the version
+	GetPacketI(uuid int32, state PacketState, version data.MinecraftVersion) PacketI
 
-	GetPacketI(uuid int32, state PacketState) PacketI
+	// creates the outgoing packet with the id it has in the version
+	GetPacketO(uuid int32, state PacketState, version data.MinecraftVersion) PacketO
 
-	/*GetPacketO(uuid int32, state PacketState) PacketO*/
+	// sends the reason to the client if its state has a disconnect packet, then stops the connection
+	Disconnect(conn Connection, reason msgs.Message)
 }
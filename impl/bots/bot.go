@@ -0,0 +1,446 @@
+package bots
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/data/msgs"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conn"
+	"github.com/golangmc/minecraft-server/impl/data/status"
+	"github.com/golangmc/minecraft-server/impl/game/auth"
+	"github.com/golangmc/minecraft-server/impl/prot"
+	"github.com/golangmc/minecraft-server/impl/prot/client"
+	"github.com/golangmc/minecraft-server/impl/prot/server"
+)
+
+// how long dialing and each step of the status exchange may take
+const timeout = 5 * time.Second
+
+// Bot is a headless player, it logs in, answers keep alives and teleports, and hands every packet it receives to Packets
+type Bot struct {
+	name    string
+	version data.MinecraftVersion
+
+	// called with the server hash before answering an encryption request, to join the session server like the vanilla client does
+	Authenticate func(hash string) error
+
+	// answers login plugin requests, the bot refuses every channel without it like a vanilla client
+	Answer func(channel string, data []byte) (response []byte, understood bool)
+
+	registry *prot.Registry
+
+	lock    sync.Mutex // guards writes to the socket, encryption, compression and the state
+	tcp     net.Conn
+	reader  *bufio.Reader
+	state   base.PacketState
+	certify certify
+	compact compact
+
+	location data.Location
+
+	packets chan base.PacketI
+	joined  chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+
+	closeOnce sync.Once
+
+	errLock sync.Mutex
+	err     error
+}
+
+func NewBot(name string, version data.MinecraftVersion) *Bot {
+	return &Bot{
+		name:    name,
+		version: version,
+
+		registry: prot.NewRegistry(),
+
+		packets: make(chan base.PacketI, 1024),
+		joined:  make(chan struct{}),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (b *Bot) Name() string {
+	return b.name
+}
+
+// Packets receives every clientbound packet the bot decodes, it is closed once the bot disconnects
+//
+// the bot stops reading while it is full, so the server eventually times out bots nobody reads from
+func (b *Bot) Packets() <-chan base.PacketI {
+	return b.packets
+}
+
+// Done is closed once the bot disconnects
+func (b *Bot) Done() <-chan struct{} {
+	return b.done
+}
+
+// Err is the reason the bot disconnected, the disconnect message if the server sent one
+func (b *Bot) Err() error {
+	b.errLock.Lock()
+	defer b.errLock.Unlock()
+
+	return b.err
+}
+
+// Location is where the server last placed the bot, or it last moved to
+func (b *Bot) Location() data.Location {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.location
+}
+
+// Join logs in to the server at address, returning once the bot is playing
+func (b *Bot) Join(address string) error {
+	if err := b.dial(address, base.LOGIN); err != nil {
+		b.fail(err)
+
+		close(b.packets)
+		close(b.done)
+
+		return err
+	}
+
+	b.SendPacket(&server.PacketILoginStart{PlayerName: b.name})
+
+	go b.readLoop()
+
+	select {
+	case <-b.joined:
+		return nil
+	case <-b.done:
+		return b.Err()
+	}
+}
+
+// Status asks the server at address for its status response, and the time a ping takes
+func (b *Bot) Status(address string) (response status.Response, ping time.Duration, err error) {
+	probe := NewBot(b.name, b.version)
+
+	if err := probe.dial(address, base.STATUS); err != nil {
+		return response, 0, err
+	}
+
+	defer probe.Stop()
+
+	_ = probe.tcp.SetDeadline(time.Now().Add(timeout))
+
+	probe.SendPacket(&server.PacketIRequest{})
+
+	packet, err := probe.readExpected(&client.PacketOResponse{})
+	if err != nil {
+		return response, 0, err
+	}
+
+	response = packet.(*client.PacketOResponse).Status
+
+	sent := time.Now()
+	probe.SendPacket(&server.PacketIPing{Ping: sent.UnixNano()})
+
+	packet, err = probe.readExpected(&client.PacketOPong{})
+	if err != nil {
+		return response, 0, err
+	}
+
+	if pong := packet.(*client.PacketOPong); pong.Ping != sent.UnixNano() {
+		return response, 0, fmt.Errorf("pong %d does not match ping %d", pong.Ping, sent.UnixNano())
+	}
+
+	return response, time.Since(sent), nil
+}
+
+// Move walks the bot to the position
+func (b *Bot) Move(position data.PositionF, onGround bool) {
+	b.lock.Lock()
+	b.location.PositionF = position
+	b.lock.Unlock()
+
+	b.SendPacket(&server.PacketIPlayerPosition{Position: position, OnGround: onGround})
+}
+
+// Chat sends a message, or a command if it starts with a slash
+func (b *Bot) Chat(message string) {
+	b.SendPacket(&server.PacketIChatMessage{Message: message})
+}
+
+// Close disconnects the bot, waiting until it stopped reading
+func (b *Bot) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.closing)
+	})
+
+	err := b.Stop()
+
+	<-b.done
+
+	return err
+}
+
+// dial connects and sends the handshake for the next state
+func (b *Bot) dial(address string, next base.PacketState) error {
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	port, err := strconv.ParseUint(portText, 10, 16)
+	if err != nil {
+		return err
+	}
+
+	tcp, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return err
+	}
+
+	b.tcp = tcp
+	b.reader = bufio.NewReader(decrypter{bot: b})
+
+	b.SendPacket(&server.PacketIHandshake{
+		Version: int32(b.version.Protocol()),
+		Host:    host,
+		Port:    uint16(port),
+		State:   next,
+	})
+
+	_ = b.SetState(next)
+
+	return nil
+}
+
+func (b *Bot) readLoop() {
+	defer close(b.done)
+	defer close(b.packets)
+	defer b.tcp.Close()
+
+	for {
+		packet, err := b.readPacket()
+		if err != nil {
+			b.fail(err)
+			return
+		}
+
+		if packet == nil {
+			continue // not decoded
+		}
+
+		b.handle(packet)
+
+		select {
+		case b.packets <- packet:
+		case <-b.closing:
+			return
+		}
+	}
+}
+
+// handle answers what a vanilla client would answer by itself
+func (b *Bot) handle(packet base.PacketI) {
+	switch packet := packet.(type) {
+	case *client.PacketOEncryptionRequest:
+		if err := b.answerEncryption(packet); err != nil {
+			b.fail(err)
+			_ = b.Stop()
+		}
+	case *client.PacketOSetCompression:
+		b.CompactUpdate(packet.Threshold)
+	case *client.PacketOLoginPluginRequest:
+		response := server.PacketILoginPluginResponse{Message: packet.MessageID}
+
+		if b.Answer != nil {
+			response.OptData, response.Success = b.Answer(packet.Channel, packet.OptData)
+		}
+
+		b.SendPacket(&response)
+	case *client.PacketOLoginSuccess:
+		_ = b.SetState(base.PLAY)
+		close(b.joined)
+	case *client.PacketODisconnect:
+		b.disconnected(packet.Reason)
+	case *client.PacketOPlayDisconnect:
+		b.disconnected(packet.Reason)
+	case *client.PacketOKeepAlive:
+		b.SendPacket(&server.PacketIKeepAlive{KeepAliveID: packet.KeepAliveID})
+	case *client.PacketOPlayerLocation:
+		b.lock.Lock()
+		b.location = teleport(b.location, packet)
+		location := b.location
+		b.lock.Unlock()
+
+		b.SendPacket(&server.PacketITeleportConfirm{TeleportID: packet.ID})
+		b.SendPacket(&server.PacketIPlayerLocation{Location: location, OnGround: true})
+	}
+}
+
+// answerEncryption sends the shared secret encrypted with the server's key, and encrypts everything after it
+func (b *Bot) answerEncryption(packet *client.PacketOEncryptionRequest) error {
+	key, err := x509.ParsePKIXPublicKey(packet.Public)
+	if err != nil {
+		return err
+	}
+
+	public, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("server key is a %T, not rsa", key)
+	}
+
+	b.CertifyValues(b.name)
+	secret := b.CertifyData()
+
+	if b.Authenticate != nil {
+		if err := b.Authenticate(auth.ServerHash(packet.Server, secret, packet.Public)); err != nil {
+			return err
+		}
+	}
+
+	encSecret, err := rsa.EncryptPKCS1v15(rand.Reader, public, secret)
+	if err != nil {
+		return err
+	}
+
+	encVerify, err := rsa.EncryptPKCS1v15(rand.Reader, public, packet.Verify)
+	if err != nil {
+		return err
+	}
+
+	b.SendPacket(&server.PacketIEncryptionResponse{Secret: encSecret, Verify: encVerify})
+	b.CertifyUpdate(secret)
+
+	return nil
+}
+
+// readPacket reads and decodes the next frame, nil if the packet is unknown to the registry
+func (b *Bot) readPacket() (base.PacketI, error) {
+	length, err := readVarInt(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if length <= 0 || length > conn.MaxFrameSize {
+		return nil, fmt.Errorf("invalid frame length %d", length)
+	}
+
+	frame := make([]byte, length)
+	if _, err := io.ReadFull(b.reader, frame); err != nil {
+		return nil, err
+	}
+
+	data, err := b.Inflate(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	bufI := conn.NewBufferWith(data)
+
+	pid := bufI.PullVrI()
+	if err := bufI.Err(); err != nil {
+		return nil, err
+	}
+
+	packet, _ := b.registry.Create(base.CLIENTBOUND, b.GetState(), b.version, pid).(base.PacketI)
+	if packet == nil {
+		return nil, nil
+	}
+
+	if err := packet.Pull(bufI, b); err != nil {
+		return nil, fmt.Errorf("malformed %s: %v", typeName(packet), err)
+	}
+
+	return packet, nil
+}
+
+// readExpected reads until a packet of the same type as expected arrives
+func (b *Bot) readExpected(expected base.PacketI) (base.PacketI, error) {
+	for {
+		packet, err := b.readPacket()
+		if err != nil {
+			return nil, err
+		}
+
+		if packet != nil && packet.UUID() == expected.UUID() {
+			return packet, nil
+		}
+	}
+}
+
+// fail keeps the first reason the bot stopped
+func (b *Bot) fail(err error) {
+	b.errLock.Lock()
+	defer b.errLock.Unlock()
+
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// disconnected keeps the reason the server gave, over writes that failed because the server closed first
+func (b *Bot) disconnected(message msgs.Message) {
+	// translated reasons have no text, the key tells what happened
+	reason := message.AsText()
+	if reason == "" {
+		reason = message.Translate
+	}
+
+	b.errLock.Lock()
+	b.err = fmt.Errorf("disconnected: %s", reason)
+	b.errLock.Unlock()
+
+	_ = b.Stop()
+}
+
+func readVarInt(reader io.ByteReader) (int32, error) {
+	var value int32
+
+	for i := 0; i < 5; i++ {
+		next, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		value |= int32(next&0x7F) << uint(7*i)
+
+		if next&0x80 == 0 {
+			return value, nil
+		}
+	}
+
+	return 0, fmt.Errorf("VarInt is longer than 5 bytes")
+}
+
+// teleport applies the location the server sent, fields flagged as relative are added to the current location
+func teleport(location data.Location, packet *client.PacketOPlayerLocation) data.Location {
+	next := packet.Location
+
+	if packet.Relative.X {
+		next.X += location.X
+	}
+	if packet.Relative.Y {
+		next.Y += location.Y
+	}
+	if packet.Relative.Z {
+		next.Z += location.Z
+	}
+	if packet.Relative.AxisX {
+		next.AxisX += location.AxisX
+	}
+	if packet.Relative.AxisY {
+		next.AxisY += location.AxisY
+	}
+
+	return next
+}
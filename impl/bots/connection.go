@@ -0,0 +1,239 @@
+package bots
+
+import (
+	"crypto/cipher"
+	"net"
+	"reflect"
+
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/game"
+	"github.com/golangmc/minecraft-server/apis/rand"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conn"
+	"github.com/golangmc/minecraft-server/impl/conn/crypto"
+)
+
+// the bot is the server's connection seen from the other side, packets are decoded and encoded with the same code
+
+type certify struct {
+	name string
+	data []byte
+
+	used    bool
+	encrypt cipher.Stream
+	decrypt cipher.Stream
+}
+
+type compact struct {
+	used bool
+	size int32
+}
+
+func (b *Bot) Address() net.Addr {
+	return b.tcp.RemoteAddr()
+}
+
+func (b *Bot) SetAddress(address net.Addr) {
+}
+
+func (b *Bot) Forwarded() *game.Profile {
+	return nil
+}
+
+func (b *Bot) SetForwarded(profile *game.Profile) {
+}
+
+func (b *Bot) GetState() base.PacketState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.state
+}
+
+func (b *Bot) SetState(state base.PacketState) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.state = state
+
+	return nil
+}
+
+func (b *Bot) GetVersion() data.MinecraftVersion {
+	return b.version
+}
+
+func (b *Bot) SetVersion(version data.MinecraftVersion) {
+	b.version = version
+}
+
+func (b *Bot) Encrypt(data []byte) (output []byte) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.encrypt(data)
+}
+
+func (b *Bot) encrypt(data []byte) (output []byte) {
+	if !b.certify.used {
+		return data
+	}
+
+	output = make([]byte, len(data))
+	b.certify.encrypt.XORKeyStream(output, data)
+
+	return
+}
+
+func (b *Bot) Decrypt(data []byte) (output []byte) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.certify.used {
+		return data
+	}
+
+	output = make([]byte, len(data))
+	b.certify.decrypt.XORKeyStream(output, data)
+
+	return
+}
+
+func (b *Bot) CertifyName() string {
+	return b.certify.name
+}
+
+func (b *Bot) CertifyData() []byte {
+	return b.certify.data
+}
+
+func (b *Bot) CertifyValues(name string) {
+	b.certify.name = name
+	b.certify.data = rand.RandomByteArray(16)
+}
+
+// CertifyUpdate encrypts everything after it, the same as the server does once it has the secret
+func (b *Bot) CertifyUpdate(secret []byte) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.certifyUpdate(secret)
+}
+
+func (b *Bot) certifyUpdate(secret []byte) {
+	encrypt, decrypt, err := crypto.NewEncryptAndDecrypt(secret)
+	if err != nil {
+		b.fail(err)
+		return
+	}
+
+	b.certify.used = true
+	b.certify.data = secret
+	b.certify.encrypt = encrypt
+	b.certify.decrypt = decrypt
+}
+
+func (b *Bot) CompactUpdate(size int32) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.compact.used = size >= 0
+	b.compact.size = size
+}
+
+func (b *Bot) Deflate(data []byte) (output []byte) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.deflate(data)
+}
+
+func (b *Bot) deflate(data []byte) (output []byte) {
+	if !b.compact.used {
+		return data
+	}
+
+	return conn.Deflate(data, b.compact.size)
+}
+
+func (b *Bot) Inflate(data []byte) (output []byte, err error) {
+	b.lock.Lock()
+	used, size := b.compact.used, b.compact.size
+	b.lock.Unlock()
+
+	if !used {
+		return data, nil
+	}
+
+	return conn.Inflate(data, size)
+}
+
+func (b *Bot) Pull(data []byte) (size int, err error) {
+	return b.tcp.Read(data)
+}
+
+func (b *Bot) Push(data []byte) (size int, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.tcp.Write(b.encrypt(data))
+}
+
+func (b *Bot) Stop() (err error) {
+	if b.tcp == nil {
+		return nil // never connected
+	}
+
+	return b.tcp.Close()
+}
+
+func (b *Bot) Closed() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// SendPacket writes a serverbound packet, packets the server does not know in the bot's version are dropped
+func (b *Bot) SendPacket(packet base.PacketO) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	pid, cont := b.registry.Map(base.SERVERBOUND, b.state, b.version, packet.UUID())
+	if !cont {
+		return
+	}
+
+	bufO := conn.NewBuffer()
+	bufO.PushVrI(pid)
+	packet.Push(bufO, b)
+
+	data := b.deflate(bufO.UAS())
+
+	temp := conn.NewBuffer()
+	temp.PushVrI(int32(len(data)))
+	temp.PushUAS(data, false)
+
+	if _, err := b.tcp.Write(b.encrypt(temp.UAS())); err != nil {
+		b.fail(err)
+	}
+}
+
+// decrypter reads from the socket, decrypting once encryption is enabled
+type decrypter struct {
+	bot *Bot
+}
+
+func (d decrypter) Read(data []byte) (int, error) {
+	size, err := d.bot.tcp.Read(data)
+
+	copy(data, d.bot.Decrypt(data[:size]))
+
+	return size, err
+}
+
+func typeName(packet base.Packet) string {
+	return reflect.TypeOf(packet).Elem().Name()
+}
@@ -0,0 +1,109 @@
+package bots
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/golangmc/minecraft-server/apis/uuid"
+	"github.com/golangmc/minecraft-server/impl/game/auth"
+)
+
+// Session is a local stand-in for the session server, bots join it and the server checks them with hasJoined
+type Session struct {
+	listener net.Listener
+	server   *http.Server
+
+	lock   sync.Mutex
+	joined map[string]session
+}
+
+type session struct {
+	hash string
+	ip   string
+}
+
+// NewSession starts a session server on a free loopback port
+func NewSession() (*Session, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{
+		listener: listener,
+		joined:   make(map[string]session),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/session/minecraft/hasJoined", s.hasJoined)
+
+	s.server = &http.Server{Handler: mux}
+
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+
+	return s, nil
+}
+
+// URL is the base url the server config points at
+func (s *Session) URL() string {
+	return "http://" + s.listener.Addr().String()
+}
+
+// Authenticate joins the bot with the server hash, as the vanilla client does before answering encryption
+func (s *Session) Authenticate(bot *Bot) func(hash string) error {
+	return func(hash string) error {
+		ip := ""
+		if tcp, ok := bot.tcp.LocalAddr().(*net.TCPAddr); ok {
+			ip = tcp.IP.String()
+		}
+
+		s.Join(bot.Name(), hash, ip)
+
+		return nil
+	}
+}
+
+// Join records that the player joined the server with the hash from ip
+func (s *Session) Join(name, hash, ip string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.joined[strings.ToLower(name)] = session{hash: hash, ip: ip}
+}
+
+// Profile is the uuid the session server hands out for a name
+func (s *Session) Profile(name string) uuid.UUID {
+	return uuid.TextToUUID("Session:" + strings.ToLower(name))
+}
+
+func (s *Session) Close() error {
+	return s.server.Close()
+}
+
+// hasJoined answers like the real session server, a profile if the player joined with the hash, no content otherwise
+func (s *Session) hasJoined(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	name := query.Get("username")
+
+	s.lock.Lock()
+	joined, ok := s.joined[strings.ToLower(name)]
+	s.lock.Unlock()
+
+	if !ok || joined.hash != query.Get("serverId") || (query.Get("ip") != "" && query.Get("ip") != joined.ip) {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(writer).Encode(auth.Auth{
+		UUID: strings.Replace(s.Profile(name).String(), "-", "", -1),
+		Name: name,
+		Prop: []auth.Prop{},
+	})
+}
@@ -1,19 +1,183 @@
 package conf
 
+import "fmt"
+
 var DefaultServerConfig = ServerConfig{
 	Network: Network{
 		Host: "0.0.0.0",
 		Port: 25565,
+
+		MaxFrameSize: 2097151,
+
+		CompressionThreshold: 256,
+
+		WriteQueueLimit: 64 << 20,
+		WriteBatching:   true,
+
+		Forwarding: NoForwarding,
+
+		ConnectionThrottle:  4000,
+		MaxConnectionsPerIP: 8,
+		PacketsPerSecond:    500,
+		BytesPerSecond:      1 << 20,
+
+		KeepAliveInterval: 10,
+		KeepAliveTimeout:  30,
+
+		HandshakeTimeout: 5,
+		LoginTimeout:     30,
+
+		ShutdownMessage: "Server closed",
+		ShutdownTimeout: 5,
+
+		EnableQuery: false,
+		QueryPort:   25565,
+
+		EnableRcon:  false,
+		RconAddress: "127.0.0.1:25575",
 	},
 	OnlineMode: false,
+
+	SessionServer:           "https://sessionserver.mojang.com",
+	SessionTimeout:          10,
+	PreventProxyConnections: false,
+
+	RejectDuplicateLogins: false,
+
+	KeySize: 1024,
+
+	Motd:       "&bA GoLang Server",
+	MaxPlayers: 20,
+	ServerIcon: "server-icon.png",
+}
+
+// Validate reports the first value the server can not start with
+func (c *ServerConfig) Validate() error {
+	if c.KeySize < 1024 {
+		return fmt.Errorf("key-size must be at least 1024 bits, got %d", c.KeySize)
+	}
+
+	return nil
 }
 
 type ServerConfig struct {
 	Network Network
 	OnlineMode bool
+
+	// the base url players are verified with in online mode
+	SessionServer string `toml:"session-server"`
+
+	// seconds the session server has to answer before the login fails
+	SessionTimeout int64 `toml:"session-timeout"`
+
+	// refuse players whose session was joined from another address than the one they connect from
+	PreventProxyConnections bool `toml:"prevent-proxy-connections"`
+
+	// refuse a player logging in while they are online, instead of kicking the session they already have
+	RejectDuplicateLogins bool `toml:"reject-duplicate-logins"`
+
+	// bits of the rsa key logins are encrypted with, at least 1024, vanilla uses 1024
+	KeySize int `toml:"key-size"`
+
+	// the description shown in the server list, & starts a color code
+	Motd string `toml:"motd"`
+
+	// the player limit shown in the server list
+	MaxPlayers int `toml:"max-players"`
+
+	// a 64x64 png shown in the server list, nothing is shown if it does not exist
+	ServerIcon string `toml:"server-icon"`
+
+	// the server icon as a data uri, read when the server loads, changing the icon needs a restart like vanilla
+	Favicon string `toml:"-"`
 }
 
 type Network struct {
 	Host string `toml:"host"`
 	Port int    `toml:"port"`
+
+	// largest length prefixed frame accepted from a client, in bytes
+	MaxFrameSize int32 `toml:"max-frame-size"`
+
+	// packets at least this large are compressed, a negative value disables compression
+	CompressionThreshold int32 `toml:"network-compression-threshold"`
+
+	// bytes a connection may have waiting to be written before the client is disconnected, 0 for no limit
+	WriteQueueLimit int64 `toml:"write-queue-limit"`
+
+	// write every queued frame before flushing, instead of flushing after each frame
+	WriteBatching bool `toml:"write-batching"`
+
+	// every packet sent and received is appended to this file as json lines, empty to disable
+	CaptureFile string `toml:"capture-file"`
+
+	// how the proxy in front of the server forwards the address and profile of players
+	Forwarding Forwarding `toml:"player-forwarding"`
+
+	// the secret shared with a velocity proxy, used to verify forwarded players
+	ForwardingSecret string `toml:"forwarding-secret"`
+
+	// every connection starts with a PROXY protocol v1 or v2 header carrying the client address
+	ProxyProtocol bool `toml:"proxy-protocol"`
+
+	// addresses and CIDR ranges allowed to send PROXY protocol headers, other connections are closed
+	ProxyTrusted []string `toml:"proxy-protocol-trusted"`
+
+	// milliseconds an address has to wait between logins, 0 to disable
+	//
+	// this and the connection limit are skipped with player forwarding, the proxy sees the real addresses
+	ConnectionThrottle int64 `toml:"connection-throttle"`
+
+	// connections a single address may hold open at once, 0 for no limit
+	MaxConnectionsPerIP int `toml:"max-connections-per-ip"`
+
+	// packets a connection may send each second before it is disconnected, 0 for no limit
+	PacketsPerSecond int `toml:"packets-per-second"`
+
+	// bytes a connection may send each second before it is disconnected, 0 for no limit
+	BytesPerSecond int `toml:"bytes-per-second"`
+
+	// seconds between keep alives sent to a player
+	KeepAliveInterval int64 `toml:"keep-alive-interval"`
+
+	// seconds a player has to answer a keep alive before they are disconnected
+	KeepAliveTimeout int64 `toml:"keep-alive-timeout"`
+
+	// seconds a client has to send its handshake and finish a status request, and a proxy its PROXY header, 0 for no limit
+	HandshakeTimeout int64 `toml:"handshake-timeout"`
+
+	// seconds a player has after the handshake to finish logging in, 0 for no limit
+	LoginTimeout int64 `toml:"login-timeout"`
+
+	// the reason players are disconnected with when the server stops
+	ShutdownMessage string `toml:"shutdown-message"`
+
+	// seconds a disconnected player has to receive everything still queued for them, when kicked or when the server stops
+	ShutdownTimeout int64 `toml:"shutdown-timeout"`
+
+	// answer the GameSpy4 query protocol used by server lists
+	EnableQuery bool `toml:"enable-query"`
+
+	// the udp port query requests are received on
+	QueryPort int `toml:"query.port"`
+
+	// run console commands sent over the source rcon protocol, only started with a password
+	EnableRcon bool `toml:"enable-rcon"`
+
+	// the host and port rcon listens on
+	RconAddress string `toml:"rcon.address"`
+
+	// the password rcon clients have to log in with
+	RconPassword string `toml:"rcon.password"`
 }
+
+type Forwarding string
+
+const (
+	// players connect to the server directly
+	NoForwarding Forwarding = "none"
+	// the handshake host carries the address, uuid and properties, as sent by bungeecord with ip_forward enabled
+	BungeeForwarding Forwarding = "bungeecord"
+	// the profile is sent in a signed login plugin response, as done by velocity's modern forwarding
+	VelocityForwarding Forwarding = "velocity"
+)
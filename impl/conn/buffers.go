@@ -4,7 +4,6 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
-	"strconv"
 
 	"github.com/golangmc/minecraft-server/apis/buff"
 	"github.com/golangmc/minecraft-server/apis/data"
@@ -30,6 +29,8 @@ type buffer struct {
 	oIndex int32
 
 	bArray []byte
+
+	err error
 }
 
 func (b *buffer) String() string {
@@ -74,6 +75,10 @@ func (b *buffer) SkpLen(delta int32) {
 	b.iIndex += delta
 }
 
+func (b *buffer) Err() error {
+	return b.err
+}
+
 // pull
 func (b *buffer) PullBit() bool {
 	return b.pullNext() != 0
@@ -84,11 +89,11 @@ func (b *buffer) PullByt() byte {
 }
 
 func (b *buffer) PullI16() int16 {
-	return int16(binary.BigEndian.Uint16(b.pullSize(4)))
+	return int16(b.PullU16())
 }
 
 func (b *buffer) PullU16() uint16 {
-	return uint16(b.pullNext())<<8 | uint16(b.pullNext())
+	return binary.BigEndian.Uint16(b.pullSize(2))
 }
 
 func (b *buffer) PullI32() int32 {
@@ -125,6 +130,15 @@ func (b *buffer) PullTxt() string {
 
 func (b *buffer) PullUAS() []byte {
 	sze := b.PullVrI()
+	if sze < 0 {
+		b.fail("negative array length %d", sze)
+		return nil
+	}
+
+	if !b.has(sze) {
+		return nil
+	}
+
 	arr := b.bArray[b.iIndex : b.iIndex+sze]
 
 	b.iIndex += sze
@@ -156,26 +170,28 @@ func (b *buffer) PullPos() data.PositionI {
 	}
 }
 
+// PullNbt reads a root compound, nil when the tag is empty
 func (b *buffer) PullNbt() *tags.NbtCompound {
 	typ := tags.Typ(b.PullByt())
 
-	fmt.Println("==type")
-	fmt.Println(typ)
-
-	if typ != tags.TAG_Compound {
-		panic("root tag must be compound") // probably shouldn't panic?
+	if typ == tags.TAG_End {
+		return nil
 	}
 
-	name := b.PullTxt()
-	if len(name) != 0 {
-		panic("root compound should have an empty name")
+	if typ != tags.TAG_Compound {
+		b.fail("root tag must be a compound, not %d", typ)
+		return nil
 	}
 
-	fmt.Println("==name")
-	fmt.Println(name)
+	// the root name is always empty, but still sent
+	b.pullNbtTxt()
 
 	tag := &tags.NbtCompound{}
-	b.pullNbt(tag)
+	b.pullNbt(tag, 0)
+
+	if b.err != nil {
+		return nil
+	}
 
 	return tag
 }
@@ -195,7 +211,7 @@ func (b *buffer) PushByt(data byte) {
 
 func (b *buffer) PushI16(data int16) {
 	b.pushNext(
-		byte(data)>>8,
+		byte(data>>8),
 		byte(data))
 }
 
@@ -301,11 +317,34 @@ func (b *buffer) PushNbt(data *tags.NbtCompound) {
 }
 
 // internal
+
+// fail keeps the first error, the rest of the buffer is skipped so every pull after it returns zero values
+func (b *buffer) fail(format string, args ...interface{}) {
+	if b.err == nil {
+		b.err = fmt.Errorf(format, args...)
+	}
+
+	b.iIndex = b.Len()
+}
+
+// has reports whether size more bytes can be pulled, failing if they can't
+func (b *buffer) has(size int32) bool {
+	if b.err != nil {
+		return false
+	}
+
+	if size > b.Len()-b.iIndex {
+		b.fail("reached end of buffer, %d bytes left of the %d needed", b.Len()-b.iIndex, size)
+		return false
+	}
+
+	return true
+}
+
 func (b *buffer) pullNext() byte {
 
-	if b.iIndex >= b.Len() {
+	if !b.has(1) {
 		return 0
-		// panic("reached end of buffer")
 	}
 
 	next := b.bArray[b.iIndex]
@@ -321,10 +360,13 @@ func (b *buffer) pullNext() byte {
 func (b *buffer) pullSize(next int) []byte {
 	bytes := make([]byte, next)
 
-	for i := 0; i < next; i++ {
-		bytes[i] = b.pullNext()
+	if !b.has(int32(next)) {
+		return bytes
 	}
 
+	copy(bytes, b.bArray[b.iIndex:])
+	b.iIndex += int32(next)
+
 	return bytes
 }
 
@@ -342,7 +384,8 @@ func (b *buffer) pullVariable(max int) int64 {
 		res |= (tmp & 0x7F) << uint(num*7)
 
 		if num++; num > max {
-			panic("VarInt > " + strconv.Itoa(max))
+			b.fail("variable length number is longer than %d bytes", max)
+			return 0
 		}
 
 		if tmp&0x80 != 0x80 {
@@ -415,7 +458,15 @@ var typeToInst = map[tags.Typ]func() tags.Nbt{
 	},
 }
 
-func (b *buffer) pullNbt(data tags.Nbt) {
+// the deepest nesting of compounds and lists accepted, as in vanilla
+const maxNbtDepth = 512
+
+func (b *buffer) pullNbt(data tags.Nbt, depth int) {
+	if depth > maxNbtDepth {
+		b.fail("nbt is nested deeper than %d", maxNbtDepth)
+		return
+	}
+
 	switch data.Type() {
 	case tags.TAG_End:
 		// nothing
@@ -424,62 +475,83 @@ func (b *buffer) pullNbt(data tags.Nbt) {
 		data.(*tags.NbtByt).Value = int8(b.PullByt())
 		break
 	case tags.TAG_Short:
-		panic("unimplemented")
+		data.(*tags.NbtI16).Value = b.PullI16()
 		break
 	case tags.TAG_Int:
-		panic("unimplemented")
+		data.(*tags.NbtI32).Value = b.PullI32()
 		break
 	case tags.TAG_Long:
-		panic("unimplemented")
+		data.(*tags.NbtI64).Value = b.PullI64()
 		break
 	case tags.TAG_Float:
-		panic("unimplemented")
+		data.(*tags.NbtF32).Value = b.PullF32()
 		break
 	case tags.TAG_Double:
-		panic("unimplemented")
+		data.(*tags.NbtF64).Value = b.PullF64()
 		break
 	case tags.TAG_Byte_Array:
-		panic("unimplemented")
+		data.(*tags.NbtArrByt).Value = asSArray(b.pullSize(int(b.pullNbtLen(1))))
 		break
 	case tags.TAG_String:
-		panic("unimplemented")
+		data.(*tags.NbtTxt).Value = b.pullNbtTxt()
 		break
 	case tags.TAG_List:
-		panic("unimplemented")
+		typ := tags.Typ(b.PullByt())
+
+		inst, ok := typeToInst[typ]
+		if !ok {
+			b.fail("unknown nbt list type %d", typ)
+			return
+		}
+
+		value := make([]tags.Nbt, 0)
+
+		for size := b.pullNbtLen(1); int32(len(value)) < size && b.err == nil; {
+			tag := inst()
+			b.pullNbt(tag, depth+1)
+
+			value = append(value, tag)
+		}
+
+		data.(*tags.NbtArrAny).NType = typ
+		data.(*tags.NbtArrAny).Value = value
 		break
 	case tags.TAG_Compound:
 		value := make(map[string]tags.Nbt)
 
-		fmt.Println("reading compound")
-
-		for {
+		for b.err == nil {
 			typ := tags.Typ(b.PullByt())
 			if typ == tags.TAG_End {
-				fmt.Println("encountered break")
 				break
 			}
 
-			fmt.Println("===type")
-			fmt.Println(typ)
+			inst, ok := typeToInst[typ]
+			if !ok {
+				b.fail("unknown nbt type %d", typ)
+				return
+			}
 
 			name := b.pullNbtTxt()
 
-			fmt.Println("===name")
-			fmt.Println(name)
+			tag := inst()
+			b.pullNbt(tag, depth+1)
 
-			inst := typeToInst[typ]()
-			b.pullNbt(inst)
-
-			value[name] = inst
+			value[name] = tag
 		}
 
 		data.(*tags.NbtCompound).Value = value
 		break
 	case tags.TAG_Int_Array:
-		panic("unimplemented")
+		value := make([]int32, b.pullNbtLen(4))
+
+		for i := 0; i < len(value); i++ {
+			value[i] = b.PullI32()
+		}
+
+		data.(*tags.NbtArrI32).Value = value
 		break
 	case tags.TAG_Long_Array:
-		value := make([]int64, b.PullI32())
+		value := make([]int64, b.pullNbtLen(8))
 
 		for i := 0; i < len(value); i++ {
 			value[i] = b.PullI64()
@@ -490,6 +562,23 @@ func (b *buffer) pullNbt(data tags.Nbt) {
 	}
 }
 
+// pullNbtLen reads the length of an array, failing if its elements of size bytes can't fit in what is left
+func (b *buffer) pullNbtLen(size int32) int32 {
+	length := b.PullI32()
+
+	if length < 0 {
+		b.fail("negative nbt array length %d", length)
+		return 0
+	}
+
+	if int64(length)*int64(size) > int64(b.Len()-b.iIndex) {
+		b.fail("nbt array of %d elements is longer than the buffer", length)
+		return 0
+	}
+
+	return length
+}
+
 func (b *buffer) pushNbt(data tags.Nbt) {
 	switch data.Type() {
 	case tags.TAG_End:
@@ -500,28 +589,20 @@ func (b *buffer) pushNbt(data tags.Nbt) {
 		break
 	case tags.TAG_Short:
 		panic("unimplemented")
-		break
 	case tags.TAG_Int:
 		panic("unimplemented")
-		break
 	case tags.TAG_Long:
 		panic("unimplemented")
-		break
 	case tags.TAG_Float:
 		panic("unimplemented")
-		break
 	case tags.TAG_Double:
 		panic("unimplemented")
-		break
 	case tags.TAG_Byte_Array:
 		panic("unimplemented")
-		break
 	case tags.TAG_String:
 		panic("unimplemented")
-		break
 	case tags.TAG_List:
 		panic("unimplemented")
-		break
 	case tags.TAG_Compound:
 		for name, tag := range data.(*tags.NbtCompound).Value {
 			b.PushByt(byte(tag.Type()))
@@ -538,7 +619,6 @@ func (b *buffer) pushNbt(data tags.Nbt) {
 		break
 	case tags.TAG_Int_Array:
 		panic("unimplemented")
-		break
 	case tags.TAG_Long_Array:
 		value := data.(*tags.NbtArrI64).Value
 
@@ -553,10 +633,13 @@ func (b *buffer) pushNbt(data tags.Nbt) {
 }
 
 func (b *buffer) pullNbtTxt() string {
-	size := b.PullI16()
-	data := b.pullSize(int(size))
+	size := b.PullU16()
+
+	if !b.has(int32(size)) {
+		return ""
+	}
 
-	return string(data)
+	return string(b.pullSize(int(size)))
 }
 
 func (b *buffer) pushNbtTxt(data string) {
@@ -0,0 +1,67 @@
+package conn
+
+import (
+	"testing"
+
+	"github.com/golangmc/minecraft-server/apis/data/tags"
+)
+
+func TestBuffer_Truncated(t *testing.T) {
+	buf := NewBufferWith([]byte{0x05, 'a', 'b'})
+
+	if text := buf.PullTxt(); text != "" || buf.Err() == nil {
+		t.Fatalf("pulled %q from a truncated string, err %v", text, buf.Err())
+	}
+
+	if value := buf.PullI64(); value != 0 {
+		t.Fatalf("pulled %d after a failure", value)
+	}
+}
+
+func TestBuffer_NegativeLength(t *testing.T) {
+	buf := NewBufferWith([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F})
+
+	if data := buf.PullUAS(); data != nil || buf.Err() == nil {
+		t.Fatalf("pulled %v with a negative length, err %v", data, buf.Err())
+	}
+}
+
+func TestBuffer_LongVarInt(t *testing.T) {
+	buf := NewBufferWith([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01})
+
+	if buf.PullVrI(); buf.Err() == nil {
+		t.Fatal("a six byte VarInt was accepted")
+	}
+}
+
+func TestBuffer_I16(t *testing.T) {
+	buf := NewBufferWith([]byte{0xFF, 0xFE, 0x01})
+
+	if value := buf.PullI16(); value != -2 || buf.InI() != 2 {
+		t.Fatalf("pulled %d, index %d", value, buf.InI())
+	}
+}
+
+func TestBuffer_Nbt(t *testing.T) {
+	buf := NewBuffer()
+	buf.PushNbt(&tags.NbtCompound{Value: map[string]tags.Nbt{
+		"flag":  &tags.NbtByt{Value: 1},
+		"longs": &tags.NbtArrI64{Value: []int64{1, 2, 3}},
+	}})
+
+	compound := NewBufferWith(buf.UAS()).PullNbt()
+	if compound == nil {
+		t.Fatal("the compound was not pulled")
+	}
+
+	if longs, ok := compound.Get("longs"); !ok || len(longs.(*tags.NbtArrI64).Value) != 3 {
+		t.Fatalf("unexpected compound %v", compound.Value)
+	}
+
+	// a long array claiming more elements than there are bytes
+	buf = NewBufferWith([]byte{byte(tags.TAG_Compound), 0, 0, byte(tags.TAG_Long_Array), 0, 1, 'a', 0x7F, 0xFF, 0xFF, 0xFF})
+
+	if compound := buf.PullNbt(); compound != nil || buf.Err() == nil {
+		t.Fatalf("pulled %v from an oversized array, err %v", compound, buf.Err())
+	}
+}
@@ -0,0 +1,110 @@
+package conn
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/golangmc/minecraft-server/impl/base"
+)
+
+// Record is a single captured packet, written to the capture file as one line of json
+type Record struct {
+	Time time.Time `json:"time"`
+	Conn string    `json:"conn"`
+
+	State     base.PacketState     `json:"state"`
+	Direction base.PacketDirection `json:"direction"`
+	Protocol  int                  `json:"protocol"`
+
+	ID     int32           `json:"id"`
+	Type   string          `json:"type,omitempty"`   // empty if the packet could not be decoded
+	Fields json.RawMessage `json:"fields,omitempty"` // the decoded packet
+
+	// the packet id and data, decrypted and inflated
+	Raw []byte `json:"raw"`
+}
+
+// Capture records every packet sent and received to a file
+type Capture struct {
+	lock   sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	closed bool // read loops may still record while the network shuts down
+}
+
+func NewCapture(path string) (*Capture, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Capture{
+		file:   file,
+		writer: bufio.NewWriter(file),
+	}, nil
+}
+
+// record writes the packet, a nil capture records nothing
+func (c *Capture) record(conn base.Connection, direction base.PacketDirection, id int32, packet base.Packet, raw []byte) {
+	if c == nil {
+		return
+	}
+
+	record := Record{
+		Time: time.Now(),
+		Conn: conn.Address().String(),
+
+		State:     conn.GetState(),
+		Direction: direction,
+		Protocol:  conn.GetVersion().Protocol(),
+
+		ID:  id,
+		Raw: raw,
+	}
+
+	if packet != nil {
+		record.Type = reflect.TypeOf(packet).Elem().Name()
+
+		// fields that can't be encoded are left out, the raw data is still there
+		if fields, err := json.Marshal(packet); err == nil {
+			record.Fields = fields
+		}
+	}
+
+	line, err := json.Marshal(&record)
+	if err != nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	_, _ = c.writer.Write(append(line, '\n'))
+	_ = c.writer.Flush()
+}
+
+// Close flushes and closes the file, packets recorded after are dropped
+func (c *Capture) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+
+	if err := c.writer.Flush(); err != nil {
+		return err
+	}
+
+	return c.file.Close()
+}
@@ -7,42 +7,115 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"reflect"
+	"sync"
+	"time"
 
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/game"
+	"github.com/golangmc/minecraft-server/apis/logs"
 	"github.com/golangmc/minecraft-server/apis/rand"
 	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conf"
 	"github.com/golangmc/minecraft-server/impl/conn/crypto"
 )
 
 type connection struct {
-	new bool
-	tcp *net.TCPConn
+	new  bool
+	tcp  *net.TCPConn
+	addr net.Addr
 
-	state base.PacketState
+	forward *game.Profile
+
+	lock    sync.Mutex // guards state, the login moves to play outside of the read loop
+	state   base.PacketState
+	version data.MinecraftVersion
+
+	packets base.Packets
 
 	certify Certify
 	compact Compact
+
+	queue   *Queue
+	drained chan struct{} // closed once the writer is done and the socket is closed
+	timeout time.Duration // how long a stopped connection may spend writing out what is still queued
+	logger  *logs.Logging
+	capture *Capture
+}
+
+func NewConnection(conn *net.TCPConn, config *conf.Network, packets base.Packets, logger *logs.Logging, capture *Capture) base.Connection {
+	return newConnection(conn, config, packets, logger, capture)
 }
 
-func NewConnection(conn *net.TCPConn) base.Connection {
-	return &connection{
+func newConnection(conn *net.TCPConn, config *conf.Network, packets base.Packets, logger *logs.Logging, capture *Capture) *connection {
+	connection := &connection{
 		new: true,
 		tcp: conn,
 
+		version: data.CurrentProtocol,
+		packets: packets,
+
 		certify: Certify{},
 		compact: Compact{},
+
+		queue:   newQueue(config.WriteQueueLimit, config.WriteBatching),
+		drained: make(chan struct{}),
+		timeout: time.Duration(config.ShutdownTimeout) * time.Second,
+		logger:  logger,
+		capture: capture,
 	}
+
+	go connection.writeLoop()
+
+	return connection
 }
 
 func (c *connection) Address() net.Addr {
+	if c.addr != nil {
+		return c.addr
+	}
+
 	return c.tcp.RemoteAddr()
 }
 
+func (c *connection) SetAddress(address net.Addr) {
+	c.addr = address
+}
+
+func (c *connection) Forwarded() *game.Profile {
+	return c.forward
+}
+
+func (c *connection) SetForwarded(profile *game.Profile) {
+	c.forward = profile
+}
+
 func (c *connection) GetState() base.PacketState {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	return c.state
 }
 
-func (c *connection) SetState(state base.PacketState) {
+func (c *connection) SetState(state base.PacketState) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.state.CanMoveTo(state) {
+		return fmt.Errorf("can not move from %v to %v", c.state, state)
+	}
+
 	c.state = state
+
+	return nil
+}
+
+func (c *connection) GetVersion() data.MinecraftVersion {
+	return c.version
+}
+
+func (c *connection) SetVersion(version data.MinecraftVersion) {
+	c.version = version
 }
 
 type Certify struct {
@@ -55,6 +128,7 @@ type Certify struct {
 	decrypt cipher.Stream
 }
 
+// Encrypt is called with queue.send held, the lock that guards the cipher
 func (c *connection) Encrypt(data []byte) (output []byte) {
 	if !c.certify.used {
 		return data
@@ -67,6 +141,9 @@ func (c *connection) Encrypt(data []byte) (output []byte) {
 }
 
 func (c *connection) Decrypt(data []byte) (output []byte) {
+	c.queue.send.Lock()
+	defer c.queue.send.Unlock()
+
 	if !c.certify.used {
 		return data
 	}
@@ -86,6 +163,9 @@ func (c *connection) CertifyData() []byte {
 }
 
 func (c *connection) CertifyUpdate(secret []byte) {
+	c.queue.send.Lock()
+	defer c.queue.send.Unlock()
+
 	encrypt, decrypt, err := crypto.NewEncryptAndDecrypt(secret)
 
 	c.certify.encrypt = encrypt
@@ -109,38 +189,89 @@ type Compact struct {
 	size int32
 }
 
+func (c *connection) CompactUpdate(size int32) {
+	c.queue.send.Lock()
+	defer c.queue.send.Unlock()
+
+	c.compact.used = size >= 0
+	c.compact.size = size
+}
+
+// Deflate is called with queue.send held, the lock that guards the threshold
 func (c *connection) Deflate(data []byte) (output []byte) {
 	if !c.compact.used {
 		return data
 	}
 
+	return Deflate(data, c.compact.size)
+}
+
+func (c *connection) Inflate(data []byte) (output []byte, err error) {
+	c.queue.send.Lock()
+	used, size := c.compact.used, c.compact.size
+	c.queue.send.Unlock()
+
+	if !used {
+		return data, nil
+	}
+
+	return Inflate(data, size)
+}
+
+// Deflate writes a packet in the compressed format, packets below the threshold are sent as is, with a data length of 0
+func Deflate(data []byte, threshold int32) []byte {
+	buf := NewBuffer()
+
+	if int32(len(data)) < threshold {
+		buf.PushVrI(0)
+		buf.PushUAS(data, false)
+
+		return buf.UAS()
+	}
+
 	var out bytes.Buffer
 
 	writer, _ := zlib.NewWriterLevel(&out, zlib.BestCompression)
 	_, _ = writer.Write(data)
 	_ = writer.Close()
 
-	output = out.Bytes()
+	buf.PushVrI(int32(len(data)))
+	buf.PushUAS(out.Bytes(), false)
 
-	return
+	return buf.UAS()
 }
 
-func (c *connection) Inflate(data []byte) (output []byte) {
-	if !c.compact.used {
-		return data
+// Inflate reads a packet in the compressed format, sent by a peer using the threshold
+func Inflate(data []byte, threshold int32) (output []byte, err error) {
+	buf := NewBufferWith(data)
+	size := buf.PullVrI()
+
+	if err := buf.Err(); err != nil {
+		return nil, err
 	}
 
-	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if size == 0 {
+		return data[buf.InI():], nil
+	}
+
+	if size < threshold || size > MaxFrameSize {
+		return nil, fmt.Errorf("invalid uncompressed length %d for threshold %d", size, threshold)
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(data[buf.InI():]))
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	var out bytes.Buffer
-	_, _ = io.Copy(&out, reader)
+	defer reader.Close()
 
-	output = out.Bytes()
+	output = make([]byte, size)
 
-	return
+	if _, err = io.ReadFull(reader, output); err != nil {
+		return nil, fmt.Errorf("failed to inflate packet of length %d: %v", size, err)
+	}
+
+	return output, nil
 }
 
 func (c *connection) Pull(data []byte) (len int, err error) {
@@ -148,26 +279,67 @@ func (c *connection) Pull(data []byte) (len int, err error) {
 	return
 }
 
-func (c *connection) Push(data []byte) (len int, err error) {
-	len, err = c.tcp.Write(data)
-	return
+func (c *connection) Push(data []byte) (size int, err error) {
+	c.queue.send.Lock()
+	defer c.queue.send.Unlock()
+
+	if err = c.enqueue(c.Encrypt(data)); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
 }
 
 func (c *connection) Stop() (err error) {
-	err = c.tcp.Close()
+	c.queue.close()
+
+	// the writer closes the socket once everything queued is written, or the deadline passes
+	err = c.tcp.SetWriteDeadline(time.Now().Add(c.timeout))
 	return
 }
 
+func (c *connection) Closed() bool {
+	return c.queue.closed()
+}
+
 func (c *connection) SendPacket(packet base.PacketO) {
 	bufO := NewBuffer()
 	temp := NewBuffer()
 
+	pid, cont := c.packets.GetPacketM(packet.UUID(), c.GetState(), c.version)
+	if !cont {
+		c.logger.DataF("not sending %v to %v, the packet does not exist in %v", reflect.TypeOf(packet), c.Address(), c.version)
+		return
+	}
+
 	// write buffer
-	bufO.PushVrI(packet.UUID())
+	bufO.PushVrI(pid)
 	packet.Push(bufO, c)
 
-	temp.PushVrI(bufO.Len())
-	temp.PushUAS(bufO.UAS(), false)
+	c.capture.record(c, base.CLIENTBOUND, pid, packet, bufO.UAS())
+
+	c.queue.send.Lock()
+	defer c.queue.send.Unlock()
 
-	_, _ = c.tcp.Write(c.Encrypt(temp.UAS()))
+	data := c.Deflate(bufO.UAS())
+
+	temp.PushVrI(int32(len(data)))
+	temp.PushUAS(data, false)
+
+	_ = c.enqueue(c.Encrypt(temp.UAS()))
+}
+
+// enqueue hands an encoded frame to the writer, disconnecting clients that fall too far behind
+func (c *connection) enqueue(data []byte) (err error) {
+	err = c.queue.push(data)
+
+	if err != nil && err != errQueueClosed {
+		c.logger.WarnF("disconnecting %v: %v", c.Address(), err)
+
+		// the client is not reading, so there is no point in waiting for the queue to drain
+		c.queue.close()
+		_ = c.tcp.Close()
+	}
+
+	return
 }
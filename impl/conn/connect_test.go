@@ -0,0 +1,72 @@
+package conn
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golangmc/minecraft-server/impl/base"
+)
+
+func TestConnection_CompactRoundTrip(t *testing.T) {
+	c := &connection{queue: newQueue(0, false)}
+	c.CompactUpdate(64)
+
+	small := []byte{0x01, 0x02, 0x03}
+	large := bytes.Repeat([]byte{0x07}, 512)
+
+	for _, data := range [][]byte{small, large} {
+		deflated := c.Deflate(data)
+
+		size := NewBufferWith(deflated).PullVrI()
+		if len(data) < 64 && size != 0 {
+			t.Fatalf("expected uncompressed data length of 0 below threshold, got %d", size)
+		}
+		if len(data) >= 64 && size != int32(len(data)) {
+			t.Fatalf("expected data length %d, got %d", len(data), size)
+		}
+
+		inflated, err := c.Inflate(deflated)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(inflated, data) {
+			t.Fatalf("round trip changed the data")
+		}
+	}
+}
+
+func TestConnection_SetState(t *testing.T) {
+	tests := []struct {
+		from base.PacketState
+		to   base.PacketState
+		ok   bool
+	}{
+		{base.SHAKE, base.STATUS, true},
+		{base.SHAKE, base.LOGIN, true},
+		{base.SHAKE, base.PLAY, false},
+		{base.SHAKE, base.SHAKE, false},
+		{base.STATUS, base.LOGIN, false},
+		{base.LOGIN, base.PLAY, true},
+		{base.LOGIN, base.STATUS, false},
+		{base.PLAY, base.SHAKE, false},
+	}
+
+	for _, test := range tests {
+		c := &connection{state: test.from}
+
+		err := c.SetState(test.to)
+		if (err == nil) != test.ok {
+			t.Fatalf("%v to %v: expected ok %v, got %v", test.from, test.to, test.ok, err)
+		}
+
+		expected := test.from
+		if test.ok {
+			expected = test.to
+		}
+
+		if c.GetState() != expected {
+			t.Fatalf("%v to %v: expected state %v, got %v", test.from, test.to, expected, c.GetState())
+		}
+	}
+}
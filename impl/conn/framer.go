@@ -0,0 +1,93 @@
+package conn
+
+import (
+	"fmt"
+)
+
+// the vanilla client and server never send frames larger than a 3 byte VarInt can describe
+const MaxFrameSize = 2097151
+
+// framer reassembles length prefixed frames out of the raw stream read from a connection
+type framer struct {
+	max int32
+
+	data []byte
+	read int
+}
+
+func newFramer(max int32) *framer {
+	if max <= 0 || max > MaxFrameSize {
+		max = MaxFrameSize
+	}
+
+	return &framer{max: max}
+}
+
+// push appends already decrypted bytes from the connection
+func (f *framer) push(data []byte) {
+	if f.read > 0 {
+		f.data = f.data[:copy(f.data, f.data[f.read:])]
+		f.read = 0
+	}
+
+	f.data = append(f.data, data...)
+}
+
+// next returns the next complete frame, or nil if more data is required
+func (f *framer) next() (frame []byte, err error) {
+	size, used, err := f.peekSize()
+	if err != nil || used == 0 {
+		return nil, err
+	}
+
+	if len(f.data)-f.read-used < int(size) {
+		return nil, nil // wait for the rest of the frame
+	}
+
+	start := f.read + used
+
+	// copy out, packets may hold on to slices of their frame after the buffer is compacted
+	frame = make([]byte, size)
+	copy(frame, f.data[start:start+int(size)])
+
+	f.read = start + int(size)
+
+	return frame, nil
+}
+
+// len returns the amount of buffered bytes which are not yet part of a returned frame
+func (f *framer) len() int {
+	return len(f.data) - f.read
+}
+
+// peekSize decodes the VarInt length prefix, used is 0 when the prefix is incomplete
+func (f *framer) peekSize() (size int32, used int, err error) {
+	var res uint32
+
+	for i := 0; i < 5; i++ {
+		if f.read+i >= len(f.data) {
+			return 0, 0, nil
+		}
+
+		tmp := f.data[f.read+i]
+		res |= uint32(tmp&0x7F) << uint(i*7)
+
+		if tmp&0x80 == 0x80 {
+			continue
+		}
+
+		size = int32(res)
+
+		if size <= 0 {
+			return 0, 0, fmt.Errorf("invalid frame length: %d", size)
+		}
+
+		if size > f.max {
+			return 0, 0, fmt.Errorf("frame length %d exceeds maximum of %d", size, f.max)
+		}
+
+		return size, i + 1, nil
+	}
+
+	return 0, 0, fmt.Errorf("frame length VarInt is longer than 5 bytes")
+}
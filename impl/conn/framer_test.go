@@ -0,0 +1,93 @@
+package conn
+
+import (
+	"bytes"
+	"testing"
+)
+
+func frameOf(payload []byte) []byte {
+	buf := NewBuffer()
+	buf.PushUAS(payload, true)
+
+	return buf.UAS()
+}
+
+func TestFramer_SplitAcrossReads(t *testing.T) {
+	payload := bytes.Repeat([]byte{0x2A}, 3000)
+	stream := frameOf(payload)
+
+	frames := newFramer(MaxFrameSize)
+
+	for i := 0; i < len(stream); i += 7 {
+		end := i + 7
+		if end > len(stream) {
+			end = len(stream)
+		}
+
+		frames.push(stream[i:end])
+
+		frame, err := frames.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if frame != nil && end != len(stream) {
+			t.Fatalf("frame returned before all bytes arrived (%d/%d)", end, len(stream))
+		}
+
+		if frame != nil && !bytes.Equal(frame, payload) {
+			t.Fatalf("reassembled frame differs from payload")
+		}
+	}
+
+	if frames.len() != 0 {
+		t.Fatalf("expected no leftover bytes, got %d", frames.len())
+	}
+}
+
+func TestFramer_ManyPerRead(t *testing.T) {
+	stream := append(frameOf([]byte{0x00, 0x01}), frameOf([]byte{0x02})...)
+	stream = append(stream, frameOf([]byte{0x03, 0x04, 0x05})[:2]...)
+
+	frames := newFramer(MaxFrameSize)
+	frames.push(stream)
+
+	expected := [][]byte{{0x00, 0x01}, {0x02}}
+
+	for _, want := range expected {
+		frame, err := frames.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(frame, want) {
+			t.Fatalf("expected %v, got %v", want, frame)
+		}
+	}
+
+	if frame, _ := frames.next(); frame != nil {
+		t.Fatalf("expected partial frame to be held back, got %v", frame)
+	}
+
+	frames.push([]byte{0x04, 0x05})
+
+	if frame, _ := frames.next(); !bytes.Equal(frame, []byte{0x03, 0x04, 0x05}) {
+		t.Fatalf("expected completed frame, got %v", frame)
+	}
+}
+
+func TestFramer_MaximumSize(t *testing.T) {
+	frames := newFramer(16)
+	frames.push(frameOf(make([]byte, 17)))
+
+	if _, err := frames.next(); err == nil {
+		t.Fatal("expected oversized frame to be rejected")
+	}
+
+	frames = newFramer(16)
+	frames.push([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x01})
+
+	if _, err := frames.next(); err == nil {
+		t.Fatal("expected overlong length prefix to be rejected")
+	}
+}
@@ -0,0 +1,88 @@
+package conn
+
+import (
+	"fmt"
+	"unicode/utf16"
+
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/data/status"
+)
+
+// the first byte of a server list ping sent by 1.4 - 1.6 clients
+const legacyPingID = 0xFE
+
+// the payload 1.4 - 1.6 clients send after the ping id
+const legacyPingPayload = 0x01
+
+// the plugin message 1.6 clients follow the ping with, carrying the host they connect to
+const legacyPluginID = 0xFA
+const legacyPingHost = "MC|PingHost"
+
+// isLegacyPing is true for the first bytes of a pre netty ping, like vanilla FE alone could be the length of a modern handshake
+func isLegacyPing(data []byte) bool {
+	if len(data) < 2 || data[0] != legacyPingID || data[1] != legacyPingPayload {
+		return false
+	}
+
+	if len(data) == 2 {
+		return true // 1.4 - 1.5
+	}
+
+	// a handshake 254 bytes long starts with FE 01 as well, 1.6 sends the ping host right after
+	channel := utf16.Encode([]rune(legacyPingHost))
+	if len(data) < 5+2*len(channel) || data[2] != legacyPluginID || int(data[3])<<8|int(data[4]) != len(channel) {
+		return false
+	}
+
+	for i, unit := range channel {
+		if uint16(data[5+2*i])<<8|uint16(data[6+2*i]) != unit {
+			return false
+		}
+	}
+
+	return true
+}
+
+// the legacy kick packet, used to carry the server list response
+const legacyKickID = 0xFF
+
+// handleLegacyPing answers the pre netty server list ping and closes the connection
+func handleLegacyPing(network *network, conn base.Connection) {
+	network.logger.DataF("legacy ping from &6%v", conn.Address())
+
+	response, ok := status.Ping(network.api, network.server, conn.Address(), data.CurrentProtocol)
+	if !ok {
+		_ = conn.Stop()
+		return
+	}
+
+	if _, err := conn.Push(legacyPingResponse(response)); err != nil {
+		network.logger.FailF("failed to push legacy ping response: %v", err)
+	}
+
+	_ = conn.Stop()
+}
+
+// legacyPingResponse formats the response as §1\0protocol\0version\0motd\0online\0max in UTF-16BE
+func legacyPingResponse(response status.Response) []byte {
+	text := fmt.Sprintf("§1\x00%d\x00%s\x00%s\x00%d\x00%d",
+		response.Version.Protocol,
+		response.Version.Name,
+		response.Description.Text,
+		response.Players.Online,
+		response.Players.Max)
+
+	units := utf16.Encode([]rune(text))
+
+	buf := NewBuffer()
+	buf.PushByt(legacyKickID)
+	buf.PushI16(int16(len(units)))
+
+	for _, unit := range units {
+		buf.PushByt(byte(unit >> 8))
+		buf.PushByt(byte(unit))
+	}
+
+	return buf.UAS()
+}
@@ -0,0 +1,55 @@
+package conn
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestIsLegacyPing(t *testing.T) {
+	// 1.6 sends the ping, the MC|PingHost plugin message and its payload in one go
+	pingHost := NewBuffer()
+	pingHost.PushByt(legacyPingID)
+	pingHost.PushByt(legacyPingPayload)
+	pingHost.PushByt(legacyPluginID)
+	pingHost.PushI16(int16(len(legacyPingHost)))
+
+	for _, unit := range utf16.Encode([]rune(legacyPingHost)) {
+		pingHost.PushI16(int16(unit))
+	}
+
+	pingHost.PushI16(7)
+
+	// a handshake 254 bytes long, its length starts with FE 01
+	packet := NewBuffer()
+	packet.PushVrI(0x00)
+	packet.PushVrI(578)
+	packet.PushTxt(strings.Repeat("a", 246))
+	packet.PushI16(25565)
+	packet.PushVrI(1)
+
+	if packet.Len() != 254 {
+		t.Fatalf("the handshake is %d bytes long", packet.Len())
+	}
+
+	handshake := NewBuffer()
+	handshake.PushVrI(int32(packet.Len()))
+	handshake.PushUAS(packet.UAS(), false)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		legacy bool
+	}{
+		{name: "1.4", data: []byte{legacyPingID, legacyPingPayload}, legacy: true},
+		{name: "1.6", data: pingHost.UAS(), legacy: true},
+		{name: "ping id alone", data: []byte{legacyPingID}},
+		{name: "handshake", data: handshake.UAS()},
+	}
+
+	for _, test := range tests {
+		if legacy := isLegacyPing(test.data); legacy != test.legacy {
+			t.Errorf("%s: expected %v, got %v", test.name, test.legacy, legacy)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package conn
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// limiter tracks the logins and open connections of every address
+type limiter struct {
+	lock sync.Mutex
+
+	logins map[string]time.Time
+	counts map[string]int
+}
+
+func newLimiter() *limiter {
+	return &limiter{
+		logins: make(map[string]time.Time),
+		counts: make(map[string]int),
+	}
+}
+
+// open counts a new connection from the host, false if it already holds max connections
+func (l *limiter) open(host string, max int) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if max > 0 && l.counts[host] >= max {
+		return false
+	}
+
+	l.counts[host]++
+
+	return true
+}
+
+// done releases a connection counted by open
+func (l *limiter) done(host string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.counts[host] <= 1 {
+		delete(l.counts, host)
+	} else {
+		l.counts[host]--
+	}
+}
+
+// login records a login from the host, false if the last one was less than interval ago
+func (l *limiter) login(host string, interval time.Duration, now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for other, last := range l.logins {
+		if now.Sub(last) >= interval {
+			delete(l.logins, other)
+		}
+	}
+
+	if _, throttled := l.logins[host]; throttled {
+		return false
+	}
+
+	l.logins[host] = now
+
+	return true
+}
+
+// rate counts what a connection sent during the current second
+type rate struct {
+	start   time.Time
+	packets int
+	bytes   int
+}
+
+// add counts packets and bytes, returning whether the limits of the current second are still kept
+func (r *rate) add(packets, bytes int, maxPackets, maxBytes int, now time.Time) bool {
+	if now.Sub(r.start) >= time.Second {
+		r.start = now
+		r.packets = 0
+		r.bytes = 0
+	}
+
+	r.packets += packets
+	r.bytes += bytes
+
+	return (maxPackets <= 0 || r.packets <= maxPackets) && (maxBytes <= 0 || r.bytes <= maxBytes)
+}
+
+// hostOf returns the ip of an address, which is what the limits are kept for
+func hostOf(addr net.Addr) string {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP.String()
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,64 @@
+package conn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_Connections(t *testing.T) {
+	limits := newLimiter()
+
+	if !limits.open("192.0.2.1", 2) || !limits.open("192.0.2.1", 2) {
+		t.Fatal("expected two connections to be allowed")
+	}
+
+	if limits.open("192.0.2.1", 2) {
+		t.Fatal("expected a third connection to be refused")
+	}
+
+	if !limits.open("192.0.2.2", 2) {
+		t.Fatal("expected another address to be allowed")
+	}
+
+	limits.done("192.0.2.1")
+
+	if !limits.open("192.0.2.1", 2) {
+		t.Fatal("expected a connection to be allowed after one closed")
+	}
+}
+
+func TestLimiter_Logins(t *testing.T) {
+	limits := newLimiter()
+	now := time.Now()
+
+	if !limits.login("192.0.2.1", time.Second, now) {
+		t.Fatal("expected the first login to be allowed")
+	}
+
+	if limits.login("192.0.2.1", time.Second, now.Add(500*time.Millisecond)) {
+		t.Fatal("expected a login within the interval to be throttled")
+	}
+
+	if !limits.login("192.0.2.1", time.Second, now.Add(time.Second)) {
+		t.Fatal("expected a login after the interval to be allowed")
+	}
+}
+
+func TestRate(t *testing.T) {
+	rates := rate{}
+	now := time.Now()
+
+	for i := 0; i < 10; i++ {
+		if !rates.add(1, 100, 10, 0, now) {
+			t.Fatalf("packet %d should be within the limit", i)
+		}
+	}
+
+	if rates.add(1, 100, 10, 0, now.Add(900*time.Millisecond)) {
+		t.Fatal("expected the eleventh packet in a second to exceed the limit")
+	}
+
+	if !rates.add(1, 100, 10, 0, now.Add(2*time.Second)) {
+		t.Fatal("expected the limit to reset after a second")
+	}
+}
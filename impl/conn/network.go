@@ -5,19 +5,51 @@ import (
 	"net"
 	"reflect"
 	"strconv"
+	"sync"
+	"time"
 
+	"github.com/golangmc/minecraft-server/apis"
 	"github.com/golangmc/minecraft-server/apis/buff"
+	"github.com/golangmc/minecraft-server/apis/data/msgs"
 	"github.com/golangmc/minecraft-server/apis/logs"
 	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conf"
 	"github.com/golangmc/minecraft-server/impl/data/system"
 )
 
+// the message paper shows clients exceeding its packet limit
+const tooManyPackets = "You are sending too many packets!"
+
+// the message clients sending something that can't be decoded are disconnected with
+const malformedPacket = "Received a malformed packet"
+
+// the messages vanilla shows clients that took too long to handshake or log in
+const (
+	timedOut  = "disconnect.timeout"
+	slowLogin = "multiplayer.disconnect.slow_login"
+)
+
 type network struct {
 	host string
 	port int
 
+	api apis.Server
+
+	config *conf.Network
+	server *conf.ServerConfig // what the server list and query show
+
 	logger  *logs.Logging
 	packets base.Packets
+	capture *Capture
+
+	trusted []*net.IPNet
+	limits  *limiter
+	query   *query
+
+	lock     sync.Mutex
+	listener *net.TCPListener
+	conns    map[*connection]bool
+	closing  bool
 
 	join chan base.PlayerAndConnection
 	quit chan base.PlayerAndConnection
@@ -25,10 +57,15 @@ type network struct {
 	report chan system.Message
 }
 
-func NewNetwork(host string, port int, packet base.Packets, report chan system.Message, join chan base.PlayerAndConnection, quit chan base.PlayerAndConnection) base.Network {
+func NewNetwork(api apis.Server, config *conf.ServerConfig, packet base.Packets, report chan system.Message, join chan base.PlayerAndConnection, quit chan base.PlayerAndConnection) base.Network {
 	return &network{
-		host: host,
-		port: port,
+		host: config.Network.Host,
+		port: config.Network.Port,
+
+		api: api,
+
+		config: &config.Network,
+		server: config,
 
 		join: join,
 		quit: quit,
@@ -37,18 +74,122 @@ func NewNetwork(host string, port int, packet base.Packets, report chan system.M
 
 		logger:  logs.NewLogging("network", logs.EveryLevel...),
 		packets: packet,
+		limits:  newLimiter(),
+
+		conns: make(map[*connection]bool),
 	}
 }
 
 func (n *network) Load() {
+	if n.config.CaptureFile != "" {
+		capture, err := NewCapture(n.config.CaptureFile)
+		if err != nil {
+			n.report <- system.Make(system.FAIL, fmt.Errorf("failed to open capture file [%v]", err))
+			return
+		}
+
+		n.capture = capture
+		n.logger.InfoF("capturing packets to %s", n.config.CaptureFile)
+	}
+
+	if n.config.ProxyProtocol {
+		trusted, err := parseTrusted(n.config.ProxyTrusted)
+		if err != nil {
+			n.report <- system.Make(system.FAIL, err)
+			return
+		}
+
+		if len(trusted) == 0 {
+			n.logger.WarnF("proxy protocol is enabled without trusted proxies, every connection will be closed")
+		}
+
+		n.trusted = trusted
+	}
+
 	if err := n.startListening(); err != nil {
 		n.report <- system.Make(system.FAIL, err)
 		return
 	}
+
+	if n.config.EnableQuery {
+		query := newQuery(n.config, n.logger, func() queryStats {
+			return serverStats(n.api, n.server)
+		})
+
+		// server lists going without the query is no reason to stop the server
+		if err := query.listen(); err != nil {
+			n.logger.FailF("query unavailable: %v", err)
+		}
+
+		n.lock.Lock()
+		n.query = query
+		n.lock.Unlock()
+	}
 }
 
 func (n *network) Kill() {
+	n.lock.Lock()
+
+	n.closing = true
+
+	listener := n.listener
+	query := n.query
+
+	conns := make([]*connection, 0, len(n.conns))
+	for conn := range n.conns {
+		conns = append(conns, conn)
+	}
+
+	n.lock.Unlock()
+
+	if listener != nil {
+		_ = listener.Close()
+	}
+
+	if query != nil {
+		query.close()
+	}
+
+	reason := msgs.New(n.config.ShutdownMessage)
+
+	for _, conn := range conns {
+		n.packets.Disconnect(conn, *reason)
+	}
+
+	timeout := time.After(time.Duration(n.config.ShutdownTimeout) * time.Second)
 
+	for index, conn := range conns {
+		select {
+		case <-conn.drained:
+		case <-timeout:
+			n.logger.WarnF("stopped waiting for %d connections to close", len(conns)-index)
+		}
+	}
+
+	if n.capture != nil {
+		_ = n.capture.Close()
+	}
+}
+
+// track adds a connection for Kill to disconnect, false if the network is already closing
+func (n *network) track(conn *connection) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.closing {
+		return false
+	}
+
+	n.conns[conn] = true
+
+	return true
+}
+
+func (n *network) untrack(conn *connection) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	delete(n.conns, conn)
 }
 
 func (n *network) startListening() error {
@@ -62,6 +203,10 @@ func (n *network) startListening() error {
 		return fmt.Errorf("failed to bind [%v]", err)
 	}
 
+	n.lock.Lock()
+	n.listener = tcp
+	n.lock.Unlock()
+
 	n.logger.InfoF("listening on %s:%d", n.host, n.port)
 
 	go func() {
@@ -69,40 +214,144 @@ func (n *network) startListening() error {
 			con, err := tcp.AcceptTCP()
 
 			if err != nil {
-				n.report <- system.Make(system.FAIL, err)
+				n.lock.Lock()
+				closing := n.closing
+				n.lock.Unlock()
+
+				if !closing {
+					n.report <- system.Make(system.FAIL, err)
+				}
 				break
 			}
 
+			if n.config.ProxyProtocol && !n.isTrusted(con.RemoteAddr()) {
+				n.logger.WarnF("closing connection from %v, it is not a trusted proxy", con.RemoteAddr())
+
+				_ = con.Close()
+				continue
+			}
+
 			_ = con.SetNoDelay(true)
 			_ = con.SetKeepAlive(true)
 
-			go handleConnect(n, NewConnection(con))
+			conn := newConnection(con, n.config, n.packets, n.logger, n.capture)
+
+			if !n.track(conn) {
+				_ = conn.Stop()
+				continue
+			}
+
+			go func() {
+				defer n.untrack(conn)
+
+				handleConnect(n, conn)
+			}()
 		}
 	}()
 
 	return nil
 }
 
-func handleConnect(network *network, conn base.Connection) {
-	network.logger.DataF("New Connection from &6%v", conn.Address())
+func (n *network) isTrusted(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
 
-	var inf []byte
+	for _, trusted := range n.trusted {
+		if trusted.Contains(tcp.IP) {
+			return true
+		}
+	}
 
-	for {
-		inf = make([]byte, 1024)
-		sze, err := conn.Pull(inf)
+	return false
+}
+
+func handleConnect(network *network, conn *connection) {
+	var data []byte // bytes already read that still need handling
+
+	if network.config.ProxyProtocol {
+		// the proxy sends its header right away, a socket left silent is not held open
+		if timeout := network.config.HandshakeTimeout; timeout > 0 {
+			_ = conn.tcp.SetReadDeadline(time.Now().Add(time.Duration(timeout) * time.Second))
+		}
+
+		rest, err := readProxyHeader(conn)
+
+		_ = conn.tcp.SetReadDeadline(time.Time{})
+
+		if err != nil {
+			network.logger.FailF("closing connection from %v: %v", conn.Address(), err)
+
+			_ = conn.Stop()
 
-		if err != nil && err.Error() == "EOF" {
 			network.quit <- base.PlayerAndConnection{
 				Player:     nil,
 				Connection: conn,
 			}
+			return
+		}
 
-			break
+		data = rest
+	}
+
+	// behind a forwarding proxy every player shares the proxy's address
+	limited := network.config.Forwarding == conf.NoForwarding
+	host := hostOf(conn.Address())
+
+	if limited {
+		if max := network.config.MaxConnectionsPerIP; !network.limits.open(host, max) {
+			network.drop(conn, *msgs.New("Too many connections from your address!"), "it already holds %d connections", max)
+			return
 		}
 
-		if err != nil || sze == 0 {
-			_ = conn.Stop()
+		defer network.limits.done(host)
+	}
+
+	network.logger.DataF("New Connection from &6%v", conn.Address())
+
+	stopShake := network.expire(conn, network.config.HandshakeTimeout, *msgs.NewTranslate(timedOut), base.SHAKE, base.STATUS)
+	defer stopShake()
+
+	stopLogin := func() {}
+	defer func() {
+		stopLogin()
+	}()
+
+	frames := newFramer(network.config.MaxFrameSize)
+	inf := make([]byte, 4096)
+	rates := rate{}
+
+	for {
+		if len(data) == 0 {
+			sze, err := conn.Pull(inf)
+
+			if err != nil && err.Error() == "EOF" {
+				_ = conn.Stop()
+
+				network.quit <- base.PlayerAndConnection{
+					Player:     nil,
+					Connection: conn,
+				}
+
+				break
+			}
+
+			if err != nil || sze == 0 {
+				_ = conn.Stop()
+
+				network.quit <- base.PlayerAndConnection{
+					Player:     nil,
+					Connection: conn,
+				}
+				break
+			}
+
+			data = conn.Decrypt(inf[:sze])
+		}
+
+		if conn.GetState() == base.SHAKE && frames.len() == 0 && isLegacyPing(data) {
+			handleLegacyPing(network, conn)
 
 			network.quit <- base.PlayerAndConnection{
 				Player:     nil,
@@ -111,47 +360,126 @@ func handleConnect(network *network, conn base.Connection) {
 			break
 		}
 
-		buf := NewBufferWith(conn.Decrypt(inf[:sze]))
-
-		// decompression
-		// decryption
-
-		if buf.UAS()[0] == 0xFE { // LEGACY PING
-			continue
+		if !rates.add(0, len(data), network.config.PacketsPerSecond, network.config.BytesPerSecond, time.Now()) {
+			network.drop(conn, *msgs.New(tooManyPackets), "it sent more than %d bytes in a second", network.config.BytesPerSecond)
+			return
 		}
 
-		packetLen := buf.PullVrI()
+		frames.push(data)
+		data = nil
 
-		bufI := NewBufferWith(buf.UAS()[buf.InI() : buf.InI()+packetLen])
-		bufO := NewBuffer()
+		for {
+			frame, err := frames.next()
 
-		handleReceive(network, conn, bufI, bufO)
+			if err == nil && frame != nil && !rates.add(1, 0, network.config.PacketsPerSecond, network.config.BytesPerSecond, time.Now()) {
+				network.drop(conn, *msgs.New(tooManyPackets), "it sent more than %d packets in a second", network.config.PacketsPerSecond)
+				return
+			}
 
-		if bufO.Len() > 1 {
-			temp := NewBuffer()
-			temp.PushVrI(bufO.Len())
+			state := conn.GetState()
 
-			comp := NewBuffer()
-			comp.PushUAS(conn.Deflate(bufO.UAS()), false)
+			if err == nil && frame != nil {
+				err = handleFrame(network, conn, frame)
+			}
 
-			temp.PushUAS(comp.UAS(), false)
+			if err == nil && state == base.SHAKE && conn.GetState() == base.LOGIN {
+				stopLogin = network.expire(conn, network.config.LoginTimeout, *msgs.NewTranslate(slowLogin), base.LOGIN)
+			}
+
+			if err == nil && limited && state == base.SHAKE && conn.GetState() == base.LOGIN && !conn.Closed() {
+				interval := time.Duration(network.config.ConnectionThrottle) * time.Millisecond
 
-			_, err := conn.Push(conn.Encrypt(temp.UAS()))
+				if interval > 0 && !network.limits.login(host, interval, time.Now()) {
+					network.drop(conn, *msgs.New("Connection throttled! Please wait before reconnecting."), "it logged in again within %v", interval)
+					return
+				}
+			}
 
 			if err != nil {
-				network.logger.Fail("Failed to push client bound packet: %v", err)
+				network.drop(conn, *msgs.New(malformedPacket), "%v", err)
+				return
+			}
+
+			if frame == nil || conn.Closed() {
+				break // wait for more data
 			}
 		}
+
+		if conn.Closed() {
+			network.quit <- base.PlayerAndConnection{
+				Player:     nil,
+				Connection: conn,
+			}
+			break
+		}
+	}
+}
+
+// expire disconnects the client if it is still in one of the states once the seconds pass, the returned func stops the timer
+func (n *network) expire(conn base.Connection, seconds int64, reason msgs.Message, states ...base.PacketState) (stop func()) {
+	if seconds <= 0 {
+		return func() {}
+	}
+
+	timer := time.AfterFunc(time.Duration(seconds)*time.Second, func() {
+		current := conn.GetState()
+
+		for _, state := range states {
+			if state == current && !conn.Closed() {
+				n.logger.WarnF("disconnecting %v: it stayed in %v for %d seconds", conn.Address(), current, seconds)
+
+				// the read loop notices the socket closing and reports the quit
+				n.packets.Disconnect(conn, reason)
+				return
+			}
+		}
+	})
+
+	return func() {
+		timer.Stop()
+	}
+}
+
+// drop disconnects a client that broke a limit, logging why
+func (n *network) drop(conn base.Connection, reason msgs.Message, format string, args ...interface{}) {
+	n.logger.WarnF("disconnecting %v: %s", conn.Address(), fmt.Sprintf(format, args...))
+
+	n.packets.Disconnect(conn, reason)
+
+	n.quit <- base.PlayerAndConnection{
+		Player:     nil,
+		Connection: conn,
+	}
+}
+
+func handleFrame(network *network, conn base.Connection, frame []byte) error {
+	data, err := conn.Inflate(frame)
+	if err != nil {
+		return err
 	}
+
+	return handleReceive(network, conn, NewBufferWith(data))
 }
 
-func handleReceive(network *network, conn base.Connection, bufI buff.Buffer, bufO buff.Buffer) {
+func handleReceive(network *network, conn base.Connection, bufI buff.Buffer) error {
 	uuid := bufI.PullVrI()
+	if err := bufI.Err(); err != nil {
+		return fmt.Errorf("malformed packet id: %v", err)
+	}
 
-	packetI := network.packets.GetPacketI(uuid, conn.GetState())
+	state := conn.GetState()
+
+	packetI := network.packets.GetPacketI(uuid, state, conn.GetVersion())
 	if packetI == nil {
-		network.logger.DataF("unable to decode %v packet with uuid: %d", conn.GetState(), uuid)
-		return
+		network.capture.record(conn, base.SERVERBOUND, uuid, nil, bufI.UAS())
+
+		// every packet before play is known, one that isn't was sent in the wrong state
+		if state != base.PLAY {
+			return fmt.Errorf("unexpected %v packet 0x%02X", state, uuid)
+		}
+
+		network.logger.DataF("unable to decode %v %v packet with uuid: %d", conn.GetVersion(), state, uuid)
+		return nil
 	}
 
 	if packetI.UUID() != 17 {
@@ -159,8 +487,26 @@ func handleReceive(network *network, conn base.Connection, bufI buff.Buffer, buf
 	}
 
 	// populate incoming packet
-	packetI.Pull(bufI, conn)
+	if err := pullPacket(packetI, bufI, conn); err != nil {
+		network.capture.record(conn, base.SERVERBOUND, uuid, nil, bufI.UAS())
+		return fmt.Errorf("malformed %v packet 0x%02X: %v", conn.GetState(), uuid, err)
+	}
+
+	network.capture.record(conn, base.SERVERBOUND, uuid, packetI, bufI.UAS())
 
 	network.packets.PubAs(packetI)
 	network.packets.PubAs(packetI, conn)
+
+	return nil
+}
+
+// pullPacket decodes the packet, a decoder that still panics is turned into an error
+func pullPacket(packet base.PacketI, reader buff.Buffer, conn base.Connection) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("decoding panicked: %v", r)
+		}
+	}()
+
+	return packet.Pull(reader, conn)
 }
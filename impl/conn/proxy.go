@@ -0,0 +1,190 @@
+package conn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/golangmc/minecraft-server/impl/base"
+)
+
+var (
+	proxyV1Prefix    = []byte("PROXY ")
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+const (
+	// the longest v1 header, including the trailing \r\n
+	proxyV1MaxLength = 107
+	// the fixed part of a v2 header, before the addresses
+	proxyV2HeaderLength = 16
+)
+
+// parseProxyHeader decodes a PROXY protocol v1 or v2 header at the start of data
+//
+// used is 0 when the header is incomplete, addr is nil when the proxy sent no client address (LOCAL and UNKNOWN)
+func parseProxyHeader(data []byte) (addr *net.TCPAddr, used int, err error) {
+	switch {
+	case hasPrefix(data, proxyV2Signature):
+		return parseProxyV2(data)
+	case hasPrefix(data, proxyV1Prefix):
+		return parseProxyV1(data)
+	default:
+		return nil, 0, fmt.Errorf("missing PROXY protocol header")
+	}
+}
+
+// hasPrefix reports whether data starts with prefix, or with the start of it if data is shorter
+func hasPrefix(data []byte, prefix []byte) bool {
+	if len(data) < len(prefix) {
+		return bytes.Equal(data, prefix[:len(data)])
+	}
+
+	return bytes.HasPrefix(data, prefix)
+}
+
+// PROXY TCP4 192.0.2.1 198.51.100.1 56324 25565\r\n
+func parseProxyV1(data []byte) (addr *net.TCPAddr, used int, err error) {
+	end := bytes.Index(data, []byte("\r\n"))
+	if end < 0 {
+		if len(data) >= proxyV1MaxLength {
+			return nil, 0, fmt.Errorf("PROXY v1 header is longer than %d bytes", proxyV1MaxLength)
+		}
+
+		return nil, 0, nil // wait for the rest of the header
+	}
+
+	used = end + 2
+	if used > proxyV1MaxLength {
+		return nil, 0, fmt.Errorf("PROXY v1 header is longer than %d bytes", proxyV1MaxLength)
+	}
+
+	fields := strings.Split(string(data[:end]), " ")
+
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, used, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, 0, fmt.Errorf("invalid PROXY v1 header: %q", data[:end])
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil {
+		return nil, 0, fmt.Errorf("invalid PROXY v1 source address: %q", fields[2])
+	}
+
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid PROXY v1 source port: %q", fields[4])
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, used, nil
+}
+
+func parseProxyV2(data []byte) (addr *net.TCPAddr, used int, err error) {
+	if len(data) < proxyV2HeaderLength {
+		return nil, 0, nil // wait for the rest of the header
+	}
+
+	if version := data[12] >> 4; version != 2 {
+		return nil, 0, fmt.Errorf("unsupported PROXY protocol version: %d", version)
+	}
+
+	used = proxyV2HeaderLength + int(binary.BigEndian.Uint16(data[14:16]))
+	if len(data) < used {
+		return nil, 0, nil // wait for the addresses
+	}
+
+	switch data[12] & 0x0F {
+	case 0x00: // LOCAL, health checks of the proxy itself
+		return nil, used, nil
+	case 0x01: // PROXY
+	default:
+		return nil, 0, fmt.Errorf("unsupported PROXY v2 command: %d", data[12]&0x0F)
+	}
+
+	body := data[proxyV2HeaderLength:used]
+
+	switch data[13] {
+	case 0x11: // TCP over IPv4
+		if len(body) < 12 {
+			return nil, 0, fmt.Errorf("PROXY v2 IPv4 addresses are truncated")
+		}
+
+		return &net.TCPAddr{IP: net.IP(body[0:4]).To16(), Port: int(binary.BigEndian.Uint16(body[8:10]))}, used, nil
+	case 0x21: // TCP over IPv6
+		if len(body) < 36 {
+			return nil, 0, fmt.Errorf("PROXY v2 IPv6 addresses are truncated")
+		}
+
+		return &net.TCPAddr{IP: append(net.IP{}, body[0:16]...), Port: int(binary.BigEndian.Uint16(body[32:34]))}, used, nil
+	default: // UDP and unix sockets carry no address the server could use
+		return nil, used, nil
+	}
+}
+
+// readProxyHeader reads the header sent by the proxy and applies the client address, returning what followed it
+func readProxyHeader(conn base.Connection) (rest []byte, err error) {
+	data := make([]byte, 0, 256)
+	inf := make([]byte, 256)
+
+	for {
+		sze, err := conn.Pull(inf)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, inf[:sze]...)
+
+		addr, used, err := parseProxyHeader(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if used == 0 {
+			continue
+		}
+
+		if addr != nil {
+			conn.SetAddress(addr)
+		}
+
+		return data[used:], nil
+	}
+}
+
+// parseTrusted turns addresses and CIDR ranges into networks
+func parseTrusted(sources []string) ([]*net.IPNet, error) {
+	trusted := make([]*net.IPNet, 0, len(sources))
+
+	for _, source := range sources {
+		if !strings.Contains(source, "/") {
+			ip := net.ParseIP(source)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy address: %q", source)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+
+			trusted = append(trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range: %q", source)
+		}
+
+		trusted = append(trusted, network)
+	}
+
+	return trusted, nil
+}
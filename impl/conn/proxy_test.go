@@ -0,0 +1,139 @@
+package conn
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golangmc/minecraft-server/apis/logs"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conf"
+)
+
+func TestParseProxyHeader_V1(t *testing.T) {
+	header := []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 25565\r\n")
+	data := append(append([]byte{}, header...), 0x10, 0x00)
+
+	for i := 1; i < len(header); i++ {
+		if _, used, err := parseProxyHeader(data[:i]); err != nil || used != 0 {
+			t.Fatalf("partial header of %d bytes: used %d, err %v", i, used, err)
+		}
+	}
+
+	addr, used, err := parseProxyHeader(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if used != len(header) || addr.String() != "192.0.2.1:56324" {
+		t.Fatalf("unexpected address %v, used %d", addr, used)
+	}
+}
+
+func TestParseProxyHeader_V2(t *testing.T) {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x21, 0x11, 0x00, 0x0C) // v2 PROXY, TCP over IPv4, 12 bytes of addresses
+	header = append(header, 192, 0, 2, 1, 198, 51, 100, 1, 0xDC, 0x04, 0x63, 0xDD)
+
+	data := append(append([]byte{}, header...), 0x10, 0x00)
+
+	for i := 1; i < len(header); i++ {
+		if _, used, err := parseProxyHeader(data[:i]); err != nil || used != 0 {
+			t.Fatalf("partial header of %d bytes: used %d, err %v", i, used, err)
+		}
+	}
+
+	addr, used, err := parseProxyHeader(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if used != len(header) || addr.String() != "192.0.2.1:56324" {
+		t.Fatalf("unexpected address %v, used %d", addr, used)
+	}
+
+	local := append([]byte{}, proxyV2Signature...)
+	local = append(local, 0x20, 0x00, 0x00, 0x00)
+
+	if addr, used, err := parseProxyHeader(local); err != nil || addr != nil || used != len(local) {
+		t.Fatalf("LOCAL header: address %v, used %d, err %v", addr, used, err)
+	}
+}
+
+func TestParseProxyHeader_Missing(t *testing.T) {
+	handshake := []byte{0x10, 0x00, 0xC2, 0x04}
+
+	if _, _, err := parseProxyHeader(handshake); err == nil {
+		t.Fatal("expected a frame without a header to be rejected")
+	}
+
+	if _, _, err := parseProxyHeader(append([]byte("PROXY "), bytes.Repeat([]byte{'A'}, proxyV1MaxLength)...)); err == nil {
+		t.Fatal("expected an overlong v1 header to be rejected")
+	}
+}
+
+func TestParseTrusted(t *testing.T) {
+	trusted, err := parseTrusted([]string{"10.0.0.0/8", "192.0.2.1", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := &network{trusted: trusted}
+
+	for addr, expected := range map[string]bool{
+		"10.1.2.3":    true,
+		"192.0.2.1":   true,
+		"192.0.2.2":   false,
+		"::1":         true,
+		"203.0.113.7": false,
+	} {
+		if n.isTrusted(&net.TCPAddr{IP: net.ParseIP(addr)}) != expected {
+			t.Fatalf("expected %s trusted to be %t", addr, expected)
+		}
+	}
+
+	if _, err := parseTrusted([]string{"not an address"}); err == nil {
+		t.Fatal("expected an invalid address to be rejected")
+	}
+}
+
+func TestHandleConnect_ProxyHeaderTimeout(t *testing.T) {
+	listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	tcp, err := listener.AcceptTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := conf.DefaultServerConfig.Network
+	config.ProxyProtocol = true
+	config.HandshakeTimeout = 1
+
+	n := &network{
+		config: &config,
+		logger: logs.NewLogging("network"),
+		quit:   make(chan base.PlayerAndConnection, 1),
+	}
+
+	// the client never sends a header
+	go handleConnect(n, newConnection(tcp, &config, nil, n.logger, nil))
+
+	select {
+	case <-n.quit:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the connection without a header was held open")
+	}
+}
@@ -0,0 +1,267 @@
+package conn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golangmc/minecraft-server/apis"
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/logs"
+	"github.com/golangmc/minecraft-server/impl/conf"
+	"github.com/golangmc/minecraft-server/impl/data/status"
+)
+
+var queryMagic = []byte{0xFE, 0xFD}
+
+const (
+	queryStat      = 0x00
+	queryHandshake = 0x09
+
+	// how long a challenge token handed out by a handshake stays valid
+	queryTokenExpiry = 30 * time.Second
+	// the session id bits clients are allowed to use
+	querySessionMask = 0x0F0F0F0F
+)
+
+// queryStats is everything a full stat reports about the server
+type queryStats struct {
+	Motd    string
+	Version string
+	Plugins string
+	Map     string
+
+	Online int
+	Max    int
+
+	Host string
+	Port int
+
+	Players []string
+}
+
+type challenge struct {
+	token int32
+	given time.Time
+}
+
+// query answers the GameSpy4 query protocol used by server lists, on its own udp port
+type query struct {
+	config *conf.Network
+	logger *logs.Logging
+
+	stats func() queryStats
+
+	lock   sync.Mutex
+	conn   *net.UDPConn
+	tokens map[string]challenge
+}
+
+func newQuery(config *conf.Network, logger *logs.Logging, stats func() queryStats) *query {
+	return &query{
+		config: config,
+		logger: logger,
+		stats:  stats,
+		tokens: make(map[string]challenge),
+	}
+}
+
+func (q *query) listen() error {
+	addr, err := net.ResolveUDPAddr("udp", q.config.Host+":"+strconv.Itoa(q.config.QueryPort))
+	if err != nil {
+		return fmt.Errorf("query address resolution failed [%v]", err)
+	}
+
+	udp, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind query [%v]", err)
+	}
+
+	q.lock.Lock()
+	q.conn = udp
+	q.lock.Unlock()
+
+	q.logger.InfoF("query listening on %s:%d", q.config.Host, q.config.QueryPort)
+
+	go func() {
+		inf := make([]byte, 1460)
+
+		for {
+			sze, from, err := udp.ReadFromUDP(inf)
+			if err != nil {
+				break // closed
+			}
+
+			response := q.answer(inf[:sze], from.IP.String(), time.Now())
+			if response == nil {
+				continue
+			}
+
+			if _, err := udp.WriteToUDP(response, from); err != nil {
+				q.logger.FailF("failed to answer query from %v: %v", from, err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (q *query) close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.conn != nil {
+		_ = q.conn.Close()
+	}
+}
+
+// answer builds the response to a single request, nil if it should be ignored
+func (q *query) answer(request []byte, host string, now time.Time) []byte {
+	if len(request) < 7 || !bytes.HasPrefix(request, queryMagic) {
+		return nil
+	}
+
+	kind := request[2]
+	session := int32(binary.BigEndian.Uint32(request[3:7])) & querySessionMask
+	payload := request[7:]
+
+	response := bytes.Buffer{}
+	response.WriteByte(kind)
+	_ = binary.Write(&response, binary.BigEndian, session)
+
+	switch kind {
+	case queryHandshake:
+		response.WriteString(strconv.Itoa(int(q.challenge(host, now))))
+		response.WriteByte(0)
+	case queryStat:
+		if len(payload) < 4 || !q.verify(host, int32(binary.BigEndian.Uint32(payload[0:4])), now) {
+			return nil
+		}
+
+		// a full stat is asked for by padding the token with 4 more bytes
+		if len(payload) >= 8 {
+			writeFullStat(&response, q.stats())
+		} else {
+			writeBasicStat(&response, q.stats())
+		}
+	default:
+		return nil
+	}
+
+	return response.Bytes()
+}
+
+// challenge hands out a new token to the address, replacing the one it had
+func (q *query) challenge(host string, now time.Time) int32 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for other, given := range q.tokens {
+		if now.Sub(given.given) > queryTokenExpiry {
+			delete(q.tokens, other)
+		}
+	}
+
+	token := rand.Int31()
+	q.tokens[host] = challenge{token: token, given: now}
+
+	return token
+}
+
+func (q *query) verify(host string, token int32, now time.Time) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	given, ok := q.tokens[host]
+
+	return ok && given.token == token && now.Sub(given.given) <= queryTokenExpiry
+}
+
+// motd, gametype, map, numplayers, maxplayers, hostport and hostip
+func writeBasicStat(buf *bytes.Buffer, stats queryStats) {
+	writeQueryString(buf, stats.Motd)
+	writeQueryString(buf, "SMP")
+	writeQueryString(buf, stats.Map)
+	writeQueryString(buf, strconv.Itoa(stats.Online))
+	writeQueryString(buf, strconv.Itoa(stats.Max))
+	_ = binary.Write(buf, binary.LittleEndian, uint16(stats.Port))
+	writeQueryString(buf, stats.Host)
+}
+
+// the key value section followed by the player names, each section ending with an empty string
+func writeFullStat(buf *bytes.Buffer, stats queryStats) {
+	buf.WriteString("splitnum\x00\x80\x00")
+
+	values := [][2]string{
+		{"hostname", stats.Motd},
+		{"gametype", "SMP"},
+		{"game_id", "MINECRAFT"},
+		{"version", stats.Version},
+		{"plugins", stats.Plugins},
+		{"map", stats.Map},
+		{"numplayers", strconv.Itoa(stats.Online)},
+		{"maxplayers", strconv.Itoa(stats.Max)},
+		{"hostport", strconv.Itoa(stats.Port)},
+		{"hostip", stats.Host},
+	}
+
+	for _, value := range values {
+		writeQueryString(buf, value[0])
+		writeQueryString(buf, value[1])
+	}
+
+	buf.WriteByte(0)
+
+	buf.WriteString("\x01player_\x00\x00")
+
+	for _, name := range stats.Players {
+		writeQueryString(buf, name)
+	}
+
+	buf.WriteByte(0)
+}
+
+func writeQueryString(buf *bytes.Buffer, text string) {
+	buf.WriteString(text)
+	buf.WriteByte(0)
+}
+
+// serverStats reads the status response and the players currently online
+func serverStats(api apis.Server, config *conf.ServerConfig) queryStats {
+	response := status.NewResponse(api, config, data.CurrentProtocol)
+
+	stats := queryStats{
+		Motd:    response.Description.Text,
+		Version: response.Version.Name,
+		Map:     "world",
+
+		Max: response.Players.Max,
+
+		Host: config.Network.Host,
+		Port: config.Network.Port,
+	}
+
+	if ip := net.ParseIP(stats.Host); stats.Host == "" || (ip != nil && ip.IsUnspecified()) {
+		stats.Host = "127.0.0.1"
+	}
+
+	stats.Plugins = "GoLang Server " + api.ServerVersion()
+
+	if level := api.GetLevel(); level != nil {
+		stats.Map = level.Name()
+	}
+
+	// every player, not the sample of the server list
+	for _, player := range api.Players() {
+		stats.Players = append(stats.Players, player.Name())
+	}
+
+	stats.Online = len(stats.Players)
+
+	return stats
+}
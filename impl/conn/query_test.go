@@ -0,0 +1,103 @@
+package conn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golangmc/minecraft-server/impl/conf"
+)
+
+func testQuery() *query {
+	return newQuery(&conf.Network{}, nil, func() queryStats {
+		return queryStats{
+			Motd:    "A GoLang Server",
+			Version: "GoLang Server",
+			Map:     "world",
+			Online:  2,
+			Max:     10,
+			Host:    "127.0.0.1",
+			Port:    25565,
+			Players: []string{"Sxtanna", "Notch"},
+		}
+	})
+}
+
+func queryRequest(kind byte, session int32, payload ...byte) []byte {
+	request := append([]byte{}, queryMagic...)
+	request = append(request, kind, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(request[3:7], uint32(session))
+
+	return append(request, payload...)
+}
+
+func queryHandshakeToken(t *testing.T, q *query, now time.Time) []byte {
+	response := q.answer(queryRequest(queryHandshake, 1), "192.0.2.1", now)
+	if len(response) < 6 || response[0] != queryHandshake || response[len(response)-1] != 0 {
+		t.Fatalf("unexpected handshake response %q", response)
+	}
+
+	token, err := strconv.ParseInt(string(response[5:len(response)-1]), 10, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, uint32(token))
+
+	return payload
+}
+
+func TestQuery_BasicStat(t *testing.T) {
+	q := testQuery()
+	now := time.Now()
+
+	token := queryHandshakeToken(t, q, now)
+
+	response := q.answer(queryRequest(queryStat, 0x7F7F7F7F, token...), "192.0.2.1", now)
+
+	expected := []byte{queryStat, 0x0F, 0x0F, 0x0F, 0x0F}
+	expected = append(expected, "A GoLang Server\x00SMP\x00world\x002\x0010\x00\xDD\x63127.0.0.1\x00"...)
+
+	if !bytes.Equal(response, expected) {
+		t.Fatalf("unexpected basic stat %q", response)
+	}
+}
+
+func TestQuery_FullStat(t *testing.T) {
+	q := testQuery()
+	now := time.Now()
+
+	token := queryHandshakeToken(t, q, now)
+
+	response := q.answer(queryRequest(queryStat, 1, append(token, 0, 0, 0, 0)...), "192.0.2.1", now)
+
+	if !bytes.Contains(response, []byte("\x00game_id\x00MINECRAFT\x00")) {
+		t.Fatalf("full stat is missing the game id: %q", response)
+	}
+
+	if !bytes.HasSuffix(response, []byte("\x00\x01player_\x00\x00Sxtanna\x00Notch\x00\x00")) {
+		t.Fatalf("full stat is missing the players: %q", response)
+	}
+}
+
+func TestQuery_Challenge(t *testing.T) {
+	q := testQuery()
+	now := time.Now()
+
+	token := queryHandshakeToken(t, q, now)
+
+	if response := q.answer(queryRequest(queryStat, 1, token...), "198.51.100.1", now); response != nil {
+		t.Fatal("the token of another address was accepted")
+	}
+
+	if response := q.answer(queryRequest(queryStat, 1, token...), "192.0.2.1", now.Add(queryTokenExpiry+time.Second)); response != nil {
+		t.Fatal("an expired token was accepted")
+	}
+
+	if response := q.answer(queryRequest(queryStat, 1, 0, 0, 0, 0), "192.0.2.1", now); response != nil {
+		t.Fatal("a wrong token was accepted")
+	}
+}
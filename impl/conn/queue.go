@@ -0,0 +1,142 @@
+package conn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var errQueueClosed = errors.New("connection is closed")
+
+// Queue holds the encoded frames of a connection until its writer goroutine sends them
+type Queue struct {
+	// serializes compression and encryption, so frames enter the queue in the order they are encoded
+	send sync.Mutex
+
+	lock sync.Mutex
+	wait *sync.Cond
+
+	data [][]byte
+	size int64
+	max  int64
+
+	batch bool
+	ended bool
+}
+
+func newQueue(max int64, batch bool) *Queue {
+	queue := &Queue{
+		max:   max,
+		batch: batch,
+	}
+
+	queue.wait = sync.NewCond(&queue.lock)
+
+	return queue
+}
+
+// push appends a frame, failing if the queue is closed or would exceed its limit
+func (q *Queue) push(data []byte) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.ended {
+		return errQueueClosed
+	}
+
+	if q.max > 0 && q.size+int64(len(data)) > q.max {
+		return fmt.Errorf("write queue limit of %d bytes exceeded", q.max)
+	}
+
+	q.data = append(q.data, data)
+	q.size += int64(len(data))
+
+	q.wait.Signal()
+
+	return nil
+}
+
+// take blocks until frames are queued, returning all of them when batching, or only the next one otherwise
+func (q *Queue) take() (data [][]byte, open bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for len(q.data) == 0 && !q.ended {
+		q.wait.Wait()
+	}
+
+	if len(q.data) == 0 {
+		return nil, false
+	}
+
+	if q.batch {
+		data, q.data = q.data, nil
+	} else {
+		data, q.data = q.data[:1], q.data[1:]
+	}
+
+	return data, true
+}
+
+// done marks frames returned by take as written
+func (q *Queue) done(data [][]byte) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, frame := range data {
+		q.size -= int64(len(frame))
+	}
+}
+
+func (q *Queue) closed() bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.ended
+}
+
+// close stops accepting frames and wakes the writer, frames already queued are still written
+func (q *Queue) close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.ended = true
+	q.wait.Broadcast()
+}
+
+// writeLoop is the only writer of the socket, it closes the socket once the queue is closed and drained
+func (c *connection) writeLoop() {
+	defer func() {
+		_ = c.tcp.Close()
+		close(c.drained)
+	}()
+
+	writer := bufio.NewWriter(c.tcp)
+
+	for {
+		data, open := c.queue.take()
+		if !open {
+			return
+		}
+
+		var err error
+
+		for _, frame := range data {
+			if _, err = writer.Write(frame); err != nil {
+				break
+			}
+		}
+
+		if err == nil {
+			err = writer.Flush()
+		}
+
+		c.queue.done(data)
+
+		if err != nil {
+			c.queue.close()
+			return
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package conn
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/game"
+	"github.com/golangmc/minecraft-server/impl/base"
+)
+
+// Replay feeds the serverbound packets of a capture back into packets, each captured address gets its own connection
+//
+// packets the handlers send are passed to sent, a handler panicking stops the replay with the record that caused it
+func Replay(reader io.Reader, packets base.Packets, sent func(conn base.Connection, packet base.PacketO)) (err error) {
+	conns := make(map[string]*replayConnection)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*MaxFrameSize) // the raw data is base64 encoded next to the decoded fields
+
+	for line := 1; scanner.Scan(); line++ {
+		record := Record{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("record %d: %v", line, err)
+		}
+
+		if record.Direction != base.SERVERBOUND {
+			continue
+		}
+
+		conn, ok := conns[record.Conn]
+		if !ok {
+			conn = newReplayConnection(record.Conn, sent)
+			conns[record.Conn] = conn
+		}
+
+		if err := replayRecord(packets, conn, &record); err != nil {
+			return fmt.Errorf("record %d: %v", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func replayRecord(packets base.Packets, conn *replayConnection, record *Record) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v packet 0x%02X panicked: %v", record.State, record.ID, r)
+		}
+	}()
+
+	// the captured state wins, so the replay stays in step even if the handlers changed since
+	version, _ := data.VersionOfProtocol(record.Protocol)
+
+	conn.SetVersion(version)
+	_ = conn.SetState(record.State)
+
+	bufI := NewBufferWith(record.Raw)
+
+	packetI := packets.GetPacketI(bufI.PullVrI(), conn.GetState(), conn.GetVersion())
+	if packetI == nil {
+		return nil // it was not decoded when captured either
+	}
+
+	if err := pullPacket(packetI, bufI, conn); err != nil {
+		return fmt.Errorf("%v packet 0x%02X: %v", record.State, record.ID, err)
+	}
+
+	packets.PubAs(packetI)
+	packets.PubAs(packetI, conn)
+
+	return nil
+}
+
+// replayConnection stands in for the captured client, nothing is encrypted, compressed or written
+type replayConnection struct {
+	lock sync.Mutex
+
+	addr net.Addr
+	sent func(conn base.Connection, packet base.PacketO)
+
+	state   base.PacketState
+	version data.MinecraftVersion
+
+	forward *game.Profile
+
+	name   string
+	data   []byte
+	closed bool
+}
+
+func newReplayConnection(address string, sent func(conn base.Connection, packet base.PacketO)) *replayConnection {
+	addr, err := net.ResolveTCPAddr("tcp", address)
+	if err != nil {
+		addr = &net.TCPAddr{}
+	}
+
+	return &replayConnection{
+		addr:    addr,
+		sent:    sent,
+		version: data.CurrentProtocol,
+	}
+}
+
+func (c *replayConnection) Address() net.Addr {
+	return c.addr
+}
+
+func (c *replayConnection) SetAddress(address net.Addr) {
+	c.addr = address
+}
+
+func (c *replayConnection) Forwarded() *game.Profile {
+	return c.forward
+}
+
+func (c *replayConnection) SetForwarded(profile *game.Profile) {
+	c.forward = profile
+}
+
+func (c *replayConnection) GetState() base.PacketState {
+	return c.state
+}
+
+// SetState accepts any state, the replay follows the states that were captured
+func (c *replayConnection) SetState(state base.PacketState) error {
+	c.state = state
+
+	return nil
+}
+
+func (c *replayConnection) GetVersion() data.MinecraftVersion {
+	return c.version
+}
+
+func (c *replayConnection) SetVersion(version data.MinecraftVersion) {
+	c.version = version
+}
+
+func (c *replayConnection) Encrypt(data []byte) (output []byte) {
+	return data
+}
+
+func (c *replayConnection) Decrypt(data []byte) (output []byte) {
+	return data
+}
+
+func (c *replayConnection) CertifyName() string {
+	return c.name
+}
+
+func (c *replayConnection) CertifyData() []byte {
+	return c.data
+}
+
+func (c *replayConnection) CertifyValues(name string) {
+	c.name = name
+	c.data = make([]byte, 4)
+}
+
+func (c *replayConnection) CertifyUpdate(secret []byte) {
+	c.data = secret
+}
+
+func (c *replayConnection) CompactUpdate(size int32) {
+}
+
+func (c *replayConnection) Deflate(data []byte) (output []byte) {
+	return data
+}
+
+func (c *replayConnection) Inflate(data []byte) (output []byte, err error) {
+	return data, nil
+}
+
+func (c *replayConnection) Pull(data []byte) (size int, err error) {
+	return 0, io.EOF
+}
+
+func (c *replayConnection) Push(data []byte) (size int, err error) {
+	return len(data), nil
+}
+
+func (c *replayConnection) Stop() (err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = true
+
+	return nil
+}
+
+func (c *replayConnection) Closed() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.closed
+}
+
+func (c *replayConnection) SendPacket(packet base.PacketO) {
+	if c.sent != nil {
+		c.sent(c, packet)
+	}
+}
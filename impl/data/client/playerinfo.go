@@ -2,7 +2,9 @@ package client
 
 import (
 	"github.com/golangmc/minecraft-server/apis/buff"
-	"github.com/golangmc/minecraft-server/apis/ents"
+	"github.com/golangmc/minecraft-server/apis/data/msgs"
+	"github.com/golangmc/minecraft-server/apis/game"
+	"github.com/golangmc/minecraft-server/apis/uuid"
 )
 
 type PlayerInfoAction int32
@@ -16,15 +18,36 @@ const (
 )
 
 type PlayerInfo interface {
-	buff.BufferPush
+	buff.BufferCodec
+}
+
+// returns an empty value for the action, used when pulling the player info packet
+func NewPlayerInfo(action PlayerInfoAction) PlayerInfo {
+	switch action {
+	case AddPlayer:
+		return &PlayerInfoAddPlayer{}
+	case UpdateGameMode:
+		return &PlayerInfoUpdateGameMode{}
+	case UpdateLatency:
+		return &PlayerInfoUpdateLatency{}
+	case UpdateDisplayName:
+		return &PlayerInfoUpdateDisplayName{}
+	case RemovePlayer:
+		return &PlayerInfoRemovePlayer{}
+	default:
+		return nil
+	}
 }
 
 type PlayerInfoAddPlayer struct {
-	Player ents.Player
+	Profile     *game.Profile
+	GameMode    game.GameMode
+	Latency     int32
+	DisplayName *msgs.Message
 }
 
 func (p *PlayerInfoAddPlayer) Push(writer buff.Buffer) {
-	prof := p.Player.GetProfile()
+	prof := p.Profile
 	writer.PushUID(prof.UUID)
 	writer.PushTxt(prof.Name)
 
@@ -42,12 +65,113 @@ func (p *PlayerInfoAddPlayer) Push(writer buff.Buffer) {
 		}
 	}
 
-	writer.PushVrI(int32(p.Player.GetGameMode()))
+	writer.PushVrI(int32(p.GameMode))
+
+	writer.PushVrI(p.Latency)
+
+	pushDisplayName(writer, p.DisplayName)
+}
+
+func (p *PlayerInfoAddPlayer) Pull(reader buff.Buffer) {
+	prof := &game.Profile{}
+	prof.UUID = reader.PullUID()
+	prof.Name = reader.PullTxt()
+
+	size := reader.PullVrI()
+
+	for i := int32(0); i < size && reader.Err() == nil; i++ {
+		prop := &game.ProfileProperty{}
+		prop.Name = reader.PullTxt()
+		prop.Value = reader.PullTxt()
+
+		if reader.PullBit() {
+			signature := reader.PullTxt()
+			prop.Signature = &signature
+		}
+
+		prof.Properties = append(prof.Properties, prop)
+	}
+
+	p.Profile = prof
 
-	writer.PushVrI(0) // update this to the player's actual ping
+	p.GameMode = game.GameMode(reader.PullVrI())
+
+	p.Latency = reader.PullVrI()
+
+	p.DisplayName = pullDisplayName(reader)
+}
 
-	writer.PushBit(false) // update this to be whether the player has a custom display name or not, write that name as json if they do
+type PlayerInfoUpdateGameMode struct {
+	UUID     uuid.UUID
+	GameMode game.GameMode
+}
+
+func (p *PlayerInfoUpdateGameMode) Push(writer buff.Buffer) {
+	writer.PushUID(p.UUID)
+	writer.PushVrI(int32(p.GameMode))
+}
+
+func (p *PlayerInfoUpdateGameMode) Pull(reader buff.Buffer) {
+	p.UUID = reader.PullUID()
+	p.GameMode = game.GameMode(reader.PullVrI())
 }
 
 type PlayerInfoUpdateLatency struct {
+	UUID    uuid.UUID
+	Latency int32
+}
+
+func (p *PlayerInfoUpdateLatency) Push(writer buff.Buffer) {
+	writer.PushUID(p.UUID)
+	writer.PushVrI(p.Latency)
+}
+
+func (p *PlayerInfoUpdateLatency) Pull(reader buff.Buffer) {
+	p.UUID = reader.PullUID()
+	p.Latency = reader.PullVrI()
+}
+
+type PlayerInfoUpdateDisplayName struct {
+	UUID        uuid.UUID
+	DisplayName *msgs.Message
+}
+
+func (p *PlayerInfoUpdateDisplayName) Push(writer buff.Buffer) {
+	writer.PushUID(p.UUID)
+	pushDisplayName(writer, p.DisplayName)
+}
+
+func (p *PlayerInfoUpdateDisplayName) Pull(reader buff.Buffer) {
+	p.UUID = reader.PullUID()
+	p.DisplayName = pullDisplayName(reader)
+}
+
+type PlayerInfoRemovePlayer struct {
+	UUID uuid.UUID
+}
+
+func (p *PlayerInfoRemovePlayer) Push(writer buff.Buffer) {
+	writer.PushUID(p.UUID)
+}
+
+func (p *PlayerInfoRemovePlayer) Pull(reader buff.Buffer) {
+	p.UUID = reader.PullUID()
+}
+
+// the display name is optional, prefixed with whether it is present
+func pushDisplayName(writer buff.Buffer, name *msgs.Message) {
+	if name == nil {
+		writer.PushBit(false)
+	} else {
+		writer.PushBit(true)
+		writer.PushTxt(name.AsJson())
+	}
+}
+
+func pullDisplayName(reader buff.Buffer) *msgs.Message {
+	if !reader.PullBit() {
+		return nil
+	}
+
+	return msgs.OfJson(reader.PullTxt())
 }
@@ -29,3 +29,14 @@ func (r *Relativity) Push(writer buff.Buffer) {
 
 	writer.PushByt(flags)
 }
+
+func (r *Relativity) Pull(reader buff.Buffer) {
+	flags := reader.PullByt()
+
+	r.X = r.Has(flags, 0x01)
+	r.Y = r.Has(flags, 0x02)
+	r.Z = r.Has(flags, 0x04)
+
+	r.AxisY = r.Has(flags, 0x08)
+	r.AxisX = r.Has(flags, 0x10)
+}
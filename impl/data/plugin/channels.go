@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/golangmc/minecraft-server/apis/chns"
+	"github.com/golangmc/minecraft-server/apis/ents"
+	"github.com/golangmc/minecraft-server/apis/uuid"
+	"github.com/golangmc/minecraft-server/impl/conn"
+)
+
+// Channels implements chns.Channels, send writes a message to the connection of a player
+type Channels struct {
+	send func(player ents.Player, message Message)
+
+	lock      sync.RWMutex
+	handlers  map[string]chns.Handler
+	typed     map[string]func() Message // the messages of this server's channels, packets decode them as chns.Raw
+	announced map[uuid.UUID]map[string]bool
+}
+
+func NewChannels(send func(player ents.Player, message Message)) *Channels {
+	return &Channels{
+		send: send,
+
+		handlers:  make(map[string]chns.Handler),
+		typed:     make(map[string]func() Message),
+		announced: make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+func (c *Channels) Register(channel string, create func() Message, handler chns.Handler) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.handlers[channel] = handler
+
+	if create != nil {
+		c.typed[channel] = create
+	}
+}
+
+func (c *Channels) Unregister(channel string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.handlers, channel)
+	delete(c.typed, channel)
+}
+
+func (c *Channels) Registered() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	channels := make([]string, 0, len(c.handlers))
+	for channel := range c.handlers {
+		channels = append(channels, channel)
+	}
+
+	sort.Strings(channels)
+
+	return channels
+}
+
+func (c *Channels) Send(player ents.Player, message Message) {
+	c.send(player, message)
+}
+
+func (c *Channels) Announced(player ents.Player) []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	channels := make([]string, 0, len(c.announced[player.UUID()]))
+	for channel := range c.announced[player.UUID()] {
+		channels = append(channels, channel)
+	}
+
+	sort.Strings(channels)
+
+	return channels
+}
+
+func (c *Channels) Listens(player ents.Player, channel string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.announced[player.UUID()][channel]
+}
+
+// Receive handles a message a player sent, register and unregister update the channels the player listens on
+func (c *Channels) Receive(player ents.Player, message Message) {
+	switch message := message.(type) {
+	case *Register:
+		c.announce(player, message.Channels, true)
+	case *Unregister:
+		c.announce(player, message.Channels, false)
+	}
+
+	c.lock.RLock()
+	handler := c.handlers[message.Chan()]
+	create := c.typed[message.Chan()]
+	c.lock.RUnlock()
+
+	// the packet only knows the vanilla channels, the message is decoded again as the type registered here
+	if raw, ok := message.(*chns.Raw); ok && create != nil {
+		message = create()
+		message.Pull(conn.NewBufferWith(raw.Data))
+	}
+
+	if handler != nil {
+		handler(player, message)
+	}
+}
+
+// Forget drops the channels of a player that left
+func (c *Channels) Forget(player ents.Player) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.announced, player.UUID())
+}
+
+func (c *Channels) announce(player ents.Player, channels []string, listens bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	announced := c.announced[player.UUID()]
+	if announced == nil {
+		announced = make(map[string]bool)
+		c.announced[player.UUID()] = announced
+	}
+
+	for _, channel := range channels {
+		if listens {
+			announced[channel] = true
+		} else {
+			delete(announced, channel)
+		}
+	}
+}
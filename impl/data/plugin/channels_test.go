@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/golangmc/minecraft-server/apis/chns"
+	"github.com/golangmc/minecraft-server/apis/ents"
+	"github.com/golangmc/minecraft-server/apis/uuid"
+	"github.com/golangmc/minecraft-server/impl/conn"
+)
+
+// channelPlayer stands in for a player, channels only use the uuid
+type channelPlayer struct {
+	ents.Player
+}
+
+func (p *channelPlayer) UUID() uuid.UUID {
+	return uuid.TextToUUID("OfflinePlayer:channels")
+}
+
+func TestChannels_Register(t *testing.T) {
+	var typed, other Message
+
+	withType := NewChannels(nil)
+	withType.Register("test:brand", func() Message { return &Brand{} }, func(player ents.Player, message Message) {
+		typed = message
+	})
+
+	withoutType := NewChannels(nil)
+	withoutType.Register("test:brand", nil, func(player ents.Player, message Message) {
+		other = message
+	})
+
+	if GetMessageForChannel("test:brand") != nil {
+		t.Fatal("a server's channel was registered for every server")
+	}
+
+	// the packet decoded the message as raw, only the vanilla channels are known to it
+	buffer := conn.NewBuffer()
+	(&Brand{Name: "plugin"}).Push(buffer)
+
+	raw := buffer.UAS()
+
+	withType.Receive(&channelPlayer{}, &chns.Raw{Channel: "test:brand", Data: raw})
+	withoutType.Receive(&channelPlayer{}, &chns.Raw{Channel: "test:brand", Data: raw})
+
+	if brand, ok := typed.(*Brand); !ok || brand.Name != "plugin" {
+		t.Fatalf("expected the registered type, got %#v", typed)
+	}
+
+	if _, ok := other.(*chns.Raw); !ok {
+		t.Fatalf("expected the other server to keep the message raw, got %#v", other)
+	}
+}
@@ -1,17 +1,16 @@
 package plugin
 
 import (
+	"strings"
+
 	"github.com/golangmc/minecraft-server/apis/buff"
+	"github.com/golangmc/minecraft-server/apis/chns"
 	"github.com/golangmc/minecraft-server/apis/data"
 )
 
-type Message interface {
-	Chan() string
-
-	buff.BufferPush
-	buff.BufferPull
-}
+type Message = chns.Message
 
+// the vanilla channels, it is never changed, plugins register their messages on Channels
 var registry = createMessageRegistry()
 
 type MessageRegistry struct {
@@ -19,7 +18,7 @@ type MessageRegistry struct {
 }
 
 func createMessageRegistry() MessageRegistry {
-	registry := MessageRegistry{make(map[string]func() Message)}
+	registry := MessageRegistry{channels: make(map[string]func() Message)}
 
 	registry.channels["minecraft:brand"] = func() Message {
 		return &Brand{}
@@ -33,9 +32,18 @@ func createMessageRegistry() MessageRegistry {
 		return &DebugNeighbors{}
 	}
 
+	registry.channels["minecraft:register"] = func() Message {
+		return &Register{}
+	}
+
+	registry.channels["minecraft:unregister"] = func() Message {
+		return &Unregister{}
+	}
+
 	return registry
 }
 
+// GetMessageForChannel creates the typed message of a vanilla channel, nil if the channel has none
 func GetMessageForChannel(channel string) Message {
 	creator := registry.channels[channel]
 	if creator == nil {
@@ -49,6 +57,8 @@ const (
 	CHANNEL_BRAND           = "minecraft:brand"
 	CHANNEL_DEBUG_PATHS     = "minecraft:debug/paths"
 	CHANNEL_DEBUG_NEIGHBORS = "minecraft:debug/neighbors_update"
+	CHANNEL_REGISTER        = "minecraft:register"
+	CHANNEL_UNREGISTER      = "minecraft:unregister"
 )
 
 // look, they're like cute little packets :D
@@ -69,6 +79,59 @@ func (b *Brand) Pull(reader buff.Buffer) {
 	b.Name = reader.PullTxt()
 }
 
+// Register announces the channels a side listens on, separated by null bytes
+type Register struct {
+	Channels []string
+}
+
+func (r *Register) Chan() string {
+	return CHANNEL_REGISTER
+}
+
+func (r *Register) Push(writer buff.Buffer) {
+	pushChannels(writer, r.Channels)
+}
+
+func (r *Register) Pull(reader buff.Buffer) {
+	r.Channels = pullChannels(reader)
+}
+
+// Unregister takes back channels announced with Register
+type Unregister struct {
+	Channels []string
+}
+
+func (u *Unregister) Chan() string {
+	return CHANNEL_UNREGISTER
+}
+
+func (u *Unregister) Push(writer buff.Buffer) {
+	pushChannels(writer, u.Channels)
+}
+
+func (u *Unregister) Pull(reader buff.Buffer) {
+	u.Channels = pullChannels(reader)
+}
+
+func pushChannels(writer buff.Buffer, channels []string) {
+	writer.PushUAS([]byte(strings.Join(channels, "\x00")), false)
+}
+
+func pullChannels(reader buff.Buffer) []string {
+	raw := chns.Raw{}
+	raw.Pull(reader)
+
+	channels := make([]string, 0)
+
+	for _, channel := range strings.Split(string(raw.Data), "\x00") {
+		if channel != "" {
+			channels = append(channels, channel)
+		}
+	}
+
+	return channels
+}
+
 type DebugPaths struct { // unused? honestly why did I do this
 	UnknownValue1 int32
 	UnknownValue2 float32
@@ -118,7 +181,7 @@ func (p *PathEntity) Pull(reader buff.Buffer) {
 	p.PSet = make([]PathPoint, 0)
 	p.PSetLen = int(reader.PullI32())
 
-	for i := 0; i < p.PSetLen; i++ {
+	for i := 0; i < p.PSetLen && reader.Err() == nil; i++ {
 		point := PathPoint{}
 		point.Pull(reader)
 
@@ -128,7 +191,7 @@ func (p *PathEntity) Pull(reader buff.Buffer) {
 	p.OSet = make([]PathPoint, 0)
 	p.OSetLen = int(reader.PullI32())
 
-	for i := 0; i < p.OSetLen; i++ {
+	for i := 0; i < p.OSetLen && reader.Err() == nil; i++ {
 		point := PathPoint{}
 		point.Pull(reader)
 
@@ -138,7 +201,7 @@ func (p *PathEntity) Pull(reader buff.Buffer) {
 	p.CSet = make([]PathPoint, 0)
 	p.CSetLen = int(reader.PullI32())
 
-	for i := 0; i < p.CSetLen; i++ {
+	for i := 0; i < p.CSetLen && reader.Err() == nil; i++ {
 		point := PathPoint{}
 		point.Pull(reader)
 
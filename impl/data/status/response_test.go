@@ -0,0 +1,63 @@
+package status
+
+import (
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeIcon(t *testing.T, dir string, size int) string {
+	path := filepath.Join(dir, strings.Repeat("x", size)+".png")
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, size, size))); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestFavicon(t *testing.T) {
+	dir, err := ioutil.TempDir("", "favicon")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	if uri, err := Favicon(writeIcon(t, dir, faviconSize)); err != nil || !strings.HasPrefix(uri, "data:image/png;base64,") {
+		t.Fatalf("unexpected favicon %q, err %v", uri, err)
+	}
+
+	if _, err := Favicon(writeIcon(t, dir, 32)); err == nil {
+		t.Fatal("a 32x32 icon was accepted")
+	}
+
+	if _, err := Favicon(filepath.Join(dir, "missing.png")); !os.IsNotExist(err) {
+		t.Fatalf("expected a missing icon to not exist, err %v", err)
+	}
+
+	// an icon that did not exist yet is read once it does
+	missing := filepath.Join(dir, "later.png")
+	if _, err := Favicon(missing); !os.IsNotExist(err) {
+		t.Fatalf("expected the icon to not exist yet, err %v", err)
+	}
+
+	if err := os.Rename(writeIcon(t, dir, faviconSize), missing); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Favicon(missing); err != nil {
+		t.Fatalf("the icon was not read again, %v", err)
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"crypto/sha1"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"strings"
-
-	"github.com/golangmc/minecraft-server/apis/urls"
+	"time"
 )
 
-const url = "https://sessionserver.mojang.com/session/minecraft/hasJoined"
+// the path below the session server that tells if a player joined
+const hasJoinedPath = "/session/minecraft/hasJoined"
 
 type Auth struct {
 	UUID string `json:"id"`
@@ -23,12 +26,29 @@ type Prop struct {
 	Sign *string `json:"signature"`
 }
 
-func RunAuthGet(secret []byte, name string, callback func(auth *Auth, err error)) {
-	go execute(generateAuthURL(name, generateAuthSHA(secret)), callback)
-}
+func execute(url string, timeout time.Duration, callback func(auth *Auth, err error)) {
+	client := http.Client{Timeout: timeout}
+
+	get, err := client.Get(url)
+	if err != nil {
+		callback(nil, err)
+		return
+	}
+
+	defer get.Body.Close()
+
+	// the session server answers without content when the player did not join
+	if get.StatusCode == http.StatusNoContent {
+		callback(nil, fmt.Errorf("the session server does not know the player"))
+		return
+	}
+
+	if get.StatusCode != http.StatusOK {
+		callback(nil, fmt.Errorf("the session server answered %s", get.Status))
+		return
+	}
 
-func execute(url string, callback func(auth *Auth, err error)) {
-	get, err := urls.GetByte(url)
+	body, err := ioutil.ReadAll(get.Body)
 	if err != nil {
 		callback(nil, err)
 		return
@@ -36,7 +56,7 @@ func execute(url string, callback func(auth *Auth, err error)) {
 
 	var auth Auth
 
-	err = json.Unmarshal(get, &auth)
+	err = json.Unmarshal(body, &auth)
 
 	if err != nil {
 		callback(nil, err)
@@ -45,16 +65,24 @@ func execute(url string, callback func(auth *Auth, err error)) {
 	}
 }
 
-func generateAuthURL(name, hash string) string {
-	return fmt.Sprintf("%s?username=%s&serverId=%s", url, name, hash)
+func generateAuthURL(server, name, hash, ip string) string {
+	query := url.Values{}
+	query.Set("username", name)
+	query.Set("serverId", hash)
+
+	if ip != "" {
+		query.Set("ip", ip)
+	}
+
+	return strings.TrimRight(server, "/") + hasJoinedPath + "?" + query.Encode()
 }
 
-func generateAuthSHA(secret []byte) string {
+// ServerHash is the id clients join and servers check with the session server, a signed hex sha1 digest
+func ServerHash(server string, secret []byte, public []byte) string {
 	sha := sha1.New()
 
-	// update with encoded secret, and encoded public
-	_, public := NewCrypt()
-
+	// update with the server id, encoded secret, and encoded public
+	sha.Write([]byte(server))
 	sha.Write(secret)
 	sha.Write(public)
 
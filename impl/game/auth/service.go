@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/golangmc/minecraft-server/impl/conf"
+)
+
+// Service holds the keypair a server encrypts logins with, it is created once per server and safe for concurrent use
+type Service struct {
+	config *conf.ServerConfig
+
+	secretKey *rsa.PrivateKey
+	publicArr []byte
+}
+
+func NewService(config *conf.ServerConfig) (*Service, error) {
+	secretKey, err := rsa.GenerateKey(rand.Reader, config.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the login key: %w", err)
+	}
+
+	secretKey.Precompute()
+	if err := secretKey.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to validate the login key: %w", err)
+	}
+
+	publicArr, err := x509.MarshalPKIXPublicKey(&secretKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the login key: %w", err)
+	}
+
+	return &Service{
+		config: config,
+
+		secretKey: secretKey,
+		publicArr: publicArr,
+	}, nil
+}
+
+// Public is the public key sent in the encryption request
+func (s *Service) Public() []byte {
+	return s.publicArr
+}
+
+func (s *Service) Decrypt(data []byte) ([]byte, error) {
+	return rsa.DecryptPKCS1v15(rand.Reader, s.secretKey, data)
+}
+
+// RunAuthGet asks the session server if the player joined, the address is only sent when proxy connections are prevented
+func (s *Service) RunAuthGet(secret []byte, name string, address net.Addr, callback func(auth *Auth, err error)) {
+	ip := ""
+	if tcp, ok := address.(*net.TCPAddr); ok && s.config.PreventProxyConnections {
+		ip = tcp.IP.String()
+	}
+
+	timeout := time.Duration(s.config.SessionTimeout) * time.Second
+
+	go execute(generateAuthURL(s.config.SessionServer, name, ServerHash("", secret, s.publicArr), ip), timeout, callback)
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"sync"
+	"testing"
+
+	"github.com/golangmc/minecraft-server/impl/conf"
+)
+
+func TestService_Decrypt(t *testing.T) {
+	config := conf.DefaultServerConfig
+
+	serviceA, err := NewService(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serviceB, err := NewService(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(serviceA.Public(), serviceB.Public()) {
+		t.Fatal("two services share a keypair")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(serviceA.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size := key.(*rsa.PublicKey).N.BitLen(); size != config.KeySize {
+		t.Fatalf("key is %d bits, expected %d", size, config.KeySize)
+	}
+
+	secret := []byte("shared secret 16")
+
+	var group sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			encrypted, err := rsa.EncryptPKCS1v15(rand.Reader, key.(*rsa.PublicKey), secret)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			decrypted, err := serviceA.Decrypt(encrypted)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if !bytes.Equal(decrypted, secret) {
+				t.Errorf("decrypted %v, expected %v", decrypted, secret)
+			}
+		}()
+	}
+
+	group.Wait()
+}
+
+func TestNewService_KeySize(t *testing.T) {
+	config := conf.DefaultServerConfig
+	config.KeySize = 0
+
+	if _, err := NewService(&config); err == nil {
+		t.Fatal("expected a key without bits to be refused")
+	}
+}
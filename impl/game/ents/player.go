@@ -1,6 +1,8 @@
 package ents
 
 import (
+	"sync/atomic"
+
 	"github.com/golangmc/minecraft-server/apis/data"
 	"github.com/golangmc/minecraft-server/apis/data/msgs"
 	"github.com/golangmc/minecraft-server/apis/ents"
@@ -19,6 +21,8 @@ type player struct {
 
 	online bool
 
+	ping int32
+
 	conn impl_base.Connection
 
 	mode     game.GameMode
@@ -69,6 +73,14 @@ func (p *player) GetProfile() *game.Profile {
 	return p.prof
 }
 
+func (p *player) GetPing() int32 {
+	return atomic.LoadInt32(&p.ping)
+}
+
+func (p *player) SetPing(ping int32) {
+	atomic.StoreInt32(&p.ping, ping)
+}
+
 func (p *player) SetConn(conn impl_base.Connection) {
 	p.conn = conn
 }
@@ -0,0 +1,193 @@
+package mode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/golangmc/minecraft-server/apis/data/msgs"
+	"github.com/golangmc/minecraft-server/apis/game"
+	"github.com/golangmc/minecraft-server/apis/uuid"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/game/auth"
+	"github.com/golangmc/minecraft-server/impl/prot/client"
+	"github.com/golangmc/minecraft-server/impl/prot/server"
+
+	apis_conn "github.com/golangmc/minecraft-server/impl/conn"
+)
+
+// the message spigot shows when bungeecord forwarding data is missing
+const bungeeMissing = "If you wish to use IP forwarding, please enable it in your BungeeCord config as well!"
+
+// parseBungeeHost splits the handshake host of a bungeecord proxy into host\0address\0uuid[\0properties]
+//
+// the profile is returned without a name, that only arrives with login start
+func parseBungeeHost(text string) (host string, addr net.IP, prof *game.Profile, err error) {
+	parts := strings.Split(text, "\x00")
+
+	if len(parts) != 3 && len(parts) != 4 {
+		return "", nil, nil, fmt.Errorf("expected 3 or 4 fields, got %d", len(parts))
+	}
+
+	host = parts[0]
+
+	if addr = net.ParseIP(parts[1]); addr == nil {
+		return "", nil, nil, fmt.Errorf("invalid address: %q", parts[1])
+	}
+
+	id, err := uuid.Parse(parts[2])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid uuid: %v", err)
+	}
+
+	prof = &game.Profile{UUID: id}
+
+	if len(parts) == 4 {
+		props := make([]auth.Prop, 0)
+
+		if err = json.Unmarshal([]byte(parts[3]), &props); err != nil {
+			return "", nil, nil, fmt.Errorf("invalid properties: %v", err)
+		}
+
+		for _, prop := range props {
+			prof.Properties = append(prof.Properties, &game.ProfileProperty{
+				Name:      prop.Name,
+				Value:     prop.Data,
+				Signature: prop.Sign,
+			})
+		}
+	}
+
+	return host, addr, prof, nil
+}
+
+// forwardBungee applies the forwarded address and profile to the connection, disconnecting it if there are none
+func forwardBungee(text string, conn base.Connection) {
+	_, addr, prof, err := parseBungeeHost(text)
+
+	if err != nil {
+		disconnectForwarding(conn, bungeeMissing)
+		return
+	}
+
+	port := 0
+	if tcp, ok := conn.Address().(*net.TCPAddr); ok {
+		port = tcp.Port
+	}
+
+	conn.SetAddress(&net.TCPAddr{IP: addr, Port: port})
+	conn.SetForwarded(prof)
+}
+
+const (
+	// the channel velocity answers with the forwarded player
+	velocityChannel = "velocity:player_info"
+	// the forwarding version requested from velocity, the first one without signed chat keys
+	velocityVersion = 1
+	// the id of the forwarding request, the only login plugin message sent during login
+	velocityMessageID = 0x42
+)
+
+// the message paper shows when a player did not connect through velocity
+const velocityMissing = "This server requires you to connect with Velocity."
+
+// requestVelocity asks the proxy for the player, the answer arrives as a login plugin response
+func requestVelocity(conn base.Connection) {
+	conn.SendPacket(&client.PacketOLoginPluginRequest{
+		MessageID: velocityMessageID,
+		Channel:   velocityChannel,
+		OptData:   []byte{velocityVersion},
+	})
+}
+
+// forwardVelocity verifies the response of the proxy and applies the forwarded address and profile
+func forwardVelocity(secret string, packet *server.PacketILoginPluginResponse, conn base.Connection) bool {
+	if !packet.Success {
+		disconnectForwarding(conn, velocityMissing)
+		return false
+	}
+
+	addr, prof, err := parseVelocityData(secret, packet.OptData)
+	if err != nil {
+		disconnectForwarding(conn, fmt.Sprintf("Unable to verify player details: %v", err))
+		return false
+	}
+
+	port := 0
+	if tcp, ok := conn.Address().(*net.TCPAddr); ok {
+		port = tcp.Port
+	}
+
+	conn.SetAddress(&net.TCPAddr{IP: addr, Port: port})
+	conn.SetForwarded(prof)
+
+	return true
+}
+
+// parseVelocityData checks the hmac at the start of the data and decodes the player after it
+func parseVelocityData(secret string, signed []byte) (addr net.IP, prof *game.Profile, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed forwarding data: %v", r)
+		}
+	}()
+
+	if secret == "" {
+		return nil, nil, fmt.Errorf("the forwarding secret is not configured")
+	}
+
+	if len(signed) < sha256.Size {
+		return nil, nil, fmt.Errorf("the forwarding data is not signed")
+	}
+
+	signature, content := signed[:sha256.Size], signed[sha256.Size:]
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(content)
+
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, nil, fmt.Errorf("the forwarding data has an invalid signature")
+	}
+
+	reader := apis_conn.NewBufferWith(content)
+
+	if version := reader.PullVrI(); version > velocityVersion {
+		return nil, nil, fmt.Errorf("unsupported forwarding version %d", version)
+	}
+
+	if addr = net.ParseIP(reader.PullTxt()); addr == nil {
+		return nil, nil, fmt.Errorf("invalid address")
+	}
+
+	prof = &game.Profile{}
+	prof.UUID = reader.PullUID()
+	prof.Name = reader.PullTxt()
+
+	size := reader.PullVrI()
+
+	for i := int32(0); i < size; i++ {
+		prop := &game.ProfileProperty{}
+		prop.Name = reader.PullTxt()
+		prop.Value = reader.PullTxt()
+
+		if reader.PullBit() {
+			signature := reader.PullTxt()
+			prop.Signature = &signature
+		}
+
+		prof.Properties = append(prof.Properties, prop)
+	}
+
+	return addr, prof, nil
+}
+
+func disconnectForwarding(conn base.Connection, reason string) {
+	conn.SendPacket(&client.PacketODisconnect{
+		Reason: *msgs.New(reason),
+	})
+
+	_ = conn.Stop()
+}
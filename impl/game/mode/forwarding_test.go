@@ -0,0 +1,99 @@
+package mode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/golangmc/minecraft-server/apis/uuid"
+
+	apis_conn "github.com/golangmc/minecraft-server/impl/conn"
+)
+
+func TestParseBungeeHost(t *testing.T) {
+	text := "play.example.com\x00203.0.113.7\x00069a79f444e94726a5befca90e38aaf5\x00" +
+		`[{"name":"textures","value":"e30=","signature":"c2ln"}]`
+
+	host, addr, prof, err := parseBungeeHost(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if host != "play.example.com" || addr.String() != "203.0.113.7" {
+		t.Fatalf("unexpected host %q and address %v", host, addr)
+	}
+
+	if prof.UUID.String() != "069a79f4-44e9-4726-a5be-fca90e38aaf5" {
+		t.Fatalf("unexpected uuid %v", prof.UUID)
+	}
+
+	if len(prof.Properties) != 1 || prof.Properties[0].Name != "textures" || *prof.Properties[0].Signature != "c2ln" {
+		t.Fatalf("unexpected properties %v", prof.Properties)
+	}
+}
+
+func TestParseBungeeHost_Missing(t *testing.T) {
+	for _, text := range []string{
+		"play.example.com",
+		"play.example.com\x00203.0.113.7",
+		"play.example.com\x00not an address\x00069a79f444e94726a5befca90e38aaf5",
+		"play.example.com\x00203.0.113.7\x00not a uuid",
+	} {
+		if _, _, _, err := parseBungeeHost(text); err == nil {
+			t.Fatalf("expected %q to be rejected", text)
+		}
+	}
+}
+
+func velocityData(secret string) []byte {
+	content := apis_conn.NewBuffer()
+	content.PushVrI(velocityVersion)
+	content.PushTxt("203.0.113.7")
+	content.PushUID(uuid.FromString("069a79f4-44e9-4726-a5be-fca90e38aaf5"))
+	content.PushTxt("Notch")
+	content.PushVrI(1)
+	content.PushTxt("textures")
+	content.PushTxt("e30=")
+	content.PushBit(true)
+	content.PushTxt("c2ln")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(content.UAS())
+
+	return append(mac.Sum(nil), content.UAS()...)
+}
+
+func TestParseVelocityData(t *testing.T) {
+	addr, prof, err := parseVelocityData("secret", velocityData("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addr.String() != "203.0.113.7" || prof.Name != "Notch" || prof.UUID.String() != "069a79f4-44e9-4726-a5be-fca90e38aaf5" {
+		t.Fatalf("unexpected address %v and profile %v", addr, prof)
+	}
+
+	if len(prof.Properties) != 1 || *prof.Properties[0].Signature != "c2ln" {
+		t.Fatalf("unexpected properties %v", prof.Properties)
+	}
+}
+
+func TestParseVelocityData_Refused(t *testing.T) {
+	tampered := velocityData("secret")
+	tampered[len(tampered)-1] ^= 0xFF
+
+	for name, data := range map[string][]byte{
+		"wrong secret": velocityData("other"),
+		"tampered":     tampered,
+		"unsigned":     velocityData("secret")[sha256.Size:],
+		"empty":        nil,
+	} {
+		if _, _, err := parseVelocityData("secret", data); err == nil {
+			t.Fatalf("expected %s data to be refused", name)
+		}
+	}
+
+	if _, _, err := parseVelocityData("", velocityData("")); err == nil {
+		t.Fatal("expected data to be refused without a configured secret")
+	}
+}
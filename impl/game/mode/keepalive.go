@@ -0,0 +1,144 @@
+package mode
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golangmc/minecraft-server/apis/data/msgs"
+	"github.com/golangmc/minecraft-server/apis/ents"
+	"github.com/golangmc/minecraft-server/apis/logs"
+	"github.com/golangmc/minecraft-server/apis/uuid"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/data/client"
+
+	client_packet "github.com/golangmc/minecraft-server/impl/prot/client"
+)
+
+// keepAlive is the last keep alive sent to a connection
+type keepAlive struct {
+	id      int64
+	sent    time.Time
+	pending bool
+}
+
+// keepAlives tracks the keep alive each connection still has to answer
+type keepAlives struct {
+	lock  sync.Mutex
+	conns map[base.Connection]*keepAlive
+}
+
+func newKeepAlives() *keepAlives {
+	return &keepAlives{conns: make(map[base.Connection]*keepAlive)}
+}
+
+// next returns the id to send to the connection, send is false while the last one is unanswered or was sent too recently
+//
+// expired is true once the unanswered keep alive is older than timeout
+func (k *keepAlives) next(conn base.Connection, interval, timeout time.Duration, now time.Time) (id int64, send bool, expired bool) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	alive, ok := k.conns[conn]
+	if !ok {
+		alive = &keepAlive{}
+		k.conns[conn] = alive
+	}
+
+	if alive.pending {
+		return 0, false, now.Sub(alive.sent) > timeout
+	}
+
+	if ok && now.Sub(alive.sent) < interval {
+		return 0, false, false
+	}
+
+	alive.id = now.UnixNano() / int64(time.Millisecond)
+	alive.sent = now
+	alive.pending = true
+
+	return alive.id, true, false
+}
+
+// answer marks the keep alive as answered, returning the round trip time, ok is false if the id was not expected
+func (k *keepAlives) answer(conn base.Connection, id int64, now time.Time) (rtt time.Duration, ok bool) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	alive, found := k.conns[conn]
+	if !found || !alive.pending || alive.id != id {
+		return 0, false
+	}
+
+	alive.pending = false
+
+	return now.Sub(alive.sent), true
+}
+
+func (k *keepAlives) remove(conn base.Connection) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	delete(k.conns, conn)
+}
+
+// tick sends the keep alives that are due and kicks players that stopped answering
+//
+// players that quit after they were listed have no connection, or a closed one, and are skipped
+func (k *keepAlives) tick(players []ents.Player, connOf func(uuid.UUID) base.Connection, interval, timeout time.Duration, now time.Time, logger *logs.Logging) {
+	for _, player := range players {
+		conn := connOf(player.UUID())
+		if conn == nil || conn.Closed() {
+			continue
+		}
+
+		id, send, expired := k.next(conn, interval, timeout, now)
+
+		if expired {
+			logger.WarnF("disconnecting %s, they did not answer a keep alive within %v", player.Name(), timeout)
+
+			kick(conn, *msgs.NewTranslate("disconnect.timeout"))
+			continue
+		}
+
+		// keep player connection alive via keep alive
+		if send {
+			conn.SendPacket(&client_packet.PacketOKeepAlive{KeepAliveID: id})
+		}
+	}
+}
+
+// sendLatency updates the tab list of every player with the pings of all players, skipping players that quit
+func sendLatency(players []ents.Player, connOf func(uuid.UUID) base.Connection) {
+	latency := make([]client.PlayerInfo, 0, len(players))
+
+	for _, player := range players {
+		latency = append(latency, &client.PlayerInfoUpdateLatency{
+			UUID:    player.UUID(),
+			Latency: player.GetPing(),
+		})
+	}
+
+	for _, player := range players {
+		conn := connOf(player.UUID())
+		if conn == nil || conn.Closed() {
+			continue
+		}
+
+		conn.SendPacket(&client_packet.PacketOPlayerInfo{
+			Action: client.UpdateLatency,
+			Values: latency,
+		})
+	}
+}
+
+// smoothPing weighs a new round trip into the ping the same way the vanilla server does
+func smoothPing(ping int32, rtt time.Duration) int32 {
+	return (ping*3 + int32(rtt/time.Millisecond)) / 4
+}
+
+// kick disconnects a player that is in the play state
+func kick(conn base.Connection, reason msgs.Message) {
+	conn.SendPacket(&client_packet.PacketOPlayDisconnect{Reason: reason})
+
+	_ = conn.Stop()
+}
@@ -0,0 +1,119 @@
+package mode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golangmc/minecraft-server/apis/ents"
+	"github.com/golangmc/minecraft-server/apis/logs"
+	"github.com/golangmc/minecraft-server/apis/uuid"
+	"github.com/golangmc/minecraft-server/impl/base"
+)
+
+func TestKeepAlives(t *testing.T) {
+	alive := newKeepAlives()
+	conn := base.Connection(nil)
+	now := time.Now()
+
+	id, send, expired := alive.next(conn, 10*time.Second, 30*time.Second, now)
+	if !send || expired {
+		t.Fatal("expected the first keep alive to be sent")
+	}
+
+	if _, send, expired := alive.next(conn, 10*time.Second, 30*time.Second, now.Add(20*time.Second)); send || expired {
+		t.Fatal("expected nothing to be sent while the keep alive is unanswered")
+	}
+
+	if _, ok := alive.answer(conn, id+1, now.Add(time.Second)); ok {
+		t.Fatal("expected an unknown id to be refused")
+	}
+
+	rtt, ok := alive.answer(conn, id, now.Add(80*time.Millisecond))
+	if !ok || rtt != 80*time.Millisecond {
+		t.Fatalf("unexpected round trip %v", rtt)
+	}
+
+	if _, send, _ := alive.next(conn, 10*time.Second, 30*time.Second, now.Add(5*time.Second)); send {
+		t.Fatal("expected the next keep alive to wait for the interval")
+	}
+
+	if _, send, _ := alive.next(conn, 10*time.Second, 30*time.Second, now.Add(10*time.Second)); !send {
+		t.Fatal("expected a keep alive after the interval")
+	}
+
+	if _, _, expired := alive.next(conn, 10*time.Second, 30*time.Second, now.Add(41*time.Second)); !expired {
+		t.Fatal("expected the unanswered keep alive to expire")
+	}
+}
+
+func TestSmoothPing(t *testing.T) {
+	if ping := smoothPing(100, 200*time.Millisecond); ping != 125 {
+		t.Fatalf("expected 125, got %d", ping)
+	}
+}
+
+// tickPlayer and tickConn stand in for a player and their connection, only what the tasks use is implemented
+type tickPlayer struct {
+	ents.Player
+	uuid uuid.UUID
+}
+
+func (p *tickPlayer) UUID() uuid.UUID {
+	return p.uuid
+}
+
+func (p *tickPlayer) Name() string {
+	return p.uuid.String()
+}
+
+func (p *tickPlayer) GetPing() int32 {
+	return 0
+}
+
+type tickConn struct {
+	base.Connection
+	sent   []base.PacketO
+	closed bool
+}
+
+func (c *tickConn) SendPacket(packet base.PacketO) {
+	c.sent = append(c.sent, packet)
+}
+
+func (c *tickConn) Closed() bool {
+	return c.closed
+}
+
+func TestKeepAlives_PlayerQuitMidTick(t *testing.T) {
+	staying := &tickPlayer{uuid: uuid.TextToUUID("staying")}
+	leaving := &tickPlayer{uuid: uuid.TextToUUID("leaving")}
+	closing := &tickPlayer{uuid: uuid.TextToUUID("closing")}
+
+	conns := map[uuid.UUID]base.Connection{
+		staying.UUID(): &tickConn{},
+		closing.UUID(): &tickConn{closed: true},
+	}
+
+	// leaving was listed, but quit before its connection was looked up
+	players := []ents.Player{leaving, closing, staying}
+	connOf := func(uuid uuid.UUID) base.Connection {
+		if conn, ok := conns[uuid]; ok {
+			return conn
+		}
+
+		return nil
+	}
+
+	alive := newKeepAlives()
+	alive.tick(players, connOf, 10*time.Second, 30*time.Second, time.Now(), logs.NewLogging("test"))
+
+	sendLatency(players, connOf)
+
+	if sent := conns[staying.UUID()].(*tickConn).sent; len(sent) != 2 {
+		t.Fatalf("expected a keep alive and the latency to reach the player still online, got %d packets", len(sent))
+	}
+
+	if sent := conns[closing.UUID()].(*tickConn).sent; len(sent) != 0 {
+		t.Fatalf("expected nothing to be sent to a closed connection, got %d packets", len(sent))
+	}
+}
@@ -0,0 +1,197 @@
+package mode
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golangmc/minecraft-server/apis"
+	apis_base "github.com/golangmc/minecraft-server/apis/base"
+	"github.com/golangmc/minecraft-server/apis/game"
+	"github.com/golangmc/minecraft-server/apis/game/event"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/prot/client"
+	"github.com/golangmc/minecraft-server/impl/prot/server"
+)
+
+// pendingLogin is a player held at login until every query a plugin sent is answered
+type pendingLogin struct {
+	logins *logins
+
+	conn base.Connection
+	prof game.Profile
+
+	lock    sync.Mutex
+	next    int32
+	waiting int // queries without a finished callback, plus one while the event is published
+	refused bool
+	queries map[int32]*pendingQuery
+}
+
+type pendingQuery struct {
+	channel  string
+	timer    *time.Timer
+	callback func(response event.LoginResponse)
+}
+
+// logins publishes the login event and finishes the logins it held once they are answered
+type logins struct {
+	api    apis.Server
+	finish func(prof game.Profile, conn base.Connection)
+
+	lock  sync.Mutex
+	conns map[base.Connection]*pendingLogin
+}
+
+func newLogins(api apis.Server, finish func(prof game.Profile, conn base.Connection)) *logins {
+	return &logins{
+		api:    api,
+		finish: finish,
+		conns:  make(map[base.Connection]*pendingLogin),
+	}
+}
+
+// begin publishes the login event, the player logs in once the queries of its handlers are answered
+func (l *logins) begin(prof game.Profile, conn base.Connection) {
+	login := &pendingLogin{
+		logins:  l,
+		conn:    conn,
+		prof:    prof,
+		waiting: 1,
+		queries: make(map[int32]*pendingQuery),
+	}
+
+	l.lock.Lock()
+	l.conns[conn] = login
+	l.lock.Unlock()
+
+	defer login.release()
+
+	l.api.Watcher().PubAs(event.PlayerLoginEvent{Profile: prof, Login: login})
+}
+
+// answer hands a login plugin response to the query it belongs to, false if nothing was waiting for it
+func (l *logins) answer(packet *server.PacketILoginPluginResponse, conn base.Connection) bool {
+	l.lock.Lock()
+	login := l.conns[conn]
+	l.lock.Unlock()
+
+	if login == nil {
+		return false
+	}
+
+	return login.resolve(packet.Message, event.LoginResponse{Understood: packet.Success, Data: packet.OptData})
+}
+
+func (p *pendingLogin) Query(channel string, payload []byte, timeout time.Duration, callback func(response event.LoginResponse)) {
+	p.lock.Lock()
+
+	if p.refused {
+		p.lock.Unlock()
+		return
+	}
+
+	p.waiting++
+
+	p.next++
+	message := p.next
+
+	query := &pendingQuery{channel: channel, callback: callback}
+	p.queries[message] = query
+
+	query.timer = time.AfterFunc(timeout, func() {
+		p.resolve(message, event.LoginResponse{TimedOut: true})
+	})
+
+	p.lock.Unlock()
+
+	p.conn.SendPacket(&client.PacketOLoginPluginRequest{
+		MessageID: message,
+		Channel:   channel,
+		OptData:   payload,
+	})
+}
+
+func (p *pendingLogin) Disconnect(reason string) {
+	p.lock.Lock()
+
+	// refused already, or logged in
+	if p.refused || p.waiting == 0 {
+		p.lock.Unlock()
+		return
+	}
+
+	p.refused = true
+
+	for message, query := range p.queries {
+		query.timer.Stop()
+		delete(p.queries, message)
+	}
+
+	p.lock.Unlock()
+
+	p.logins.forget(p.conn)
+
+	disconnectForwarding(p.conn, reason)
+}
+
+// resolve runs the callback of the query once, whichever of the answer and the timeout comes first
+func (p *pendingLogin) resolve(message int32, response event.LoginResponse) bool {
+	p.lock.Lock()
+
+	query, ok := p.queries[message]
+	if ok {
+		delete(p.queries, message)
+		query.timer.Stop()
+	}
+
+	p.lock.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	response.Channel = query.channel
+
+	p.run(query.callback, response)
+
+	return true
+}
+
+// run calls a query callback, a panicking callback refuses the login
+func (p *pendingLogin) run(callback func(response event.LoginResponse), response event.LoginResponse) {
+	defer p.release()
+
+	if err := apis_base.Attempt(func() { callback(response) }); err != nil {
+		p.Disconnect(fmt.Sprintf("Login failed: %v", err))
+	}
+}
+
+// release finishes the login once nothing is waiting anymore
+func (p *pendingLogin) release() {
+	p.lock.Lock()
+
+	p.waiting--
+	done := p.waiting == 0 && !p.refused
+
+	p.lock.Unlock()
+
+	if !done {
+		return
+	}
+
+	p.logins.forget(p.conn)
+
+	if p.conn.Closed() {
+		return
+	}
+
+	p.logins.finish(p.prof, p.conn)
+}
+
+func (l *logins) forget(conn base.Connection) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.conns, conn)
+}
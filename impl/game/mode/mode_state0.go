@@ -1,8 +1,12 @@
 package mode
 
 import (
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/data/msgs"
 	"github.com/golangmc/minecraft-server/apis/util"
 	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conf"
+	"github.com/golangmc/minecraft-server/impl/prot/client"
 	"github.com/golangmc/minecraft-server/impl/prot/server"
 )
 
@@ -10,10 +14,45 @@ import (
  * handshake
  */
 
-func HandleState0(watcher util.Watcher) {
+func HandleState0(config *conf.ServerConfig, watcher util.Watcher) {
 
 	watcher.SubAs(func(packet *server.PacketIHandshake, conn base.Connection) {
-		conn.SetState(packet.State)
+		version, ok := data.VersionOfProtocol(int(packet.Version))
+
+		if ok {
+			conn.SetVersion(version)
+		}
+
+		// the client only picks between status and login, anything else ends the connection
+		if err := conn.SetState(packet.State); err != nil {
+			_ = conn.Stop()
+			return
+		}
+
+		// status requests are still answered, the response tells the client which protocol to use
+		if !ok && packet.State == base.LOGIN {
+			rejectVersion(packet.Version, conn)
+			return
+		}
+
+		// the proxy only forwards players logging in, status requests carry a plain host
+		if config.Network.Forwarding == conf.BungeeForwarding && packet.State == base.LOGIN {
+			forwardBungee(packet.Host, conn)
+		}
+	})
+
+}
+
+func rejectVersion(protocol int32, conn base.Connection) {
+	reason := "multiplayer.disconnect.outdated_server"
+
+	if int(protocol) < data.SupportedVersions[0].Protocol() {
+		reason = "multiplayer.disconnect.outdated_client"
+	}
+
+	conn.SendPacket(&client.PacketODisconnect{
+		Reason: *msgs.NewTranslate(reason, data.CurrentProtocol.String()),
 	})
 
+	_ = conn.Stop()
 }
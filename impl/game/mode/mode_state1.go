@@ -1,8 +1,10 @@
 package mode
 
 import (
+	"github.com/golangmc/minecraft-server/apis"
 	"github.com/golangmc/minecraft-server/apis/util"
 	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conf"
 	"github.com/golangmc/minecraft-server/impl/data/status"
 	"github.com/golangmc/minecraft-server/impl/prot/client"
 	"github.com/golangmc/minecraft-server/impl/prot/server"
@@ -12,11 +14,17 @@ import (
  * status
  */
 
-func HandleState1(watcher util.Watcher) {
+func HandleState1(api apis.Server, config *conf.ServerConfig, watcher util.Watcher) {
 
 	watcher.SubAs(func(packet *server.PacketIRequest, conn base.Connection) {
-		response := client.PacketOResponse{Status: status.DefaultResponse()}
-		conn.SendPacket(&response)
+		// the connection keeps the current version when the client's is unsupported
+		response, ok := status.Ping(api, config, conn.Address(), conn.GetVersion())
+		if !ok {
+			_ = conn.Stop()
+			return
+		}
+
+		conn.SendPacket(&client.PacketOResponse{Status: response})
 	})
 
 	watcher.SubAs(func(packet *server.PacketIPing, conn base.Connection) {
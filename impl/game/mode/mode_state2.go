@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/golangmc/minecraft-server/apis"
 	"github.com/golangmc/minecraft-server/impl/conf"
+	"time"
 
 	"github.com/golangmc/minecraft-server/apis/data/chat"
 	"github.com/golangmc/minecraft-server/apis/data/msgs"
@@ -22,12 +23,50 @@ import (
  * login
  */
 
-func HandleState2(config *conf.ServerConfig, watcher util.Watcher, join chan base.PlayerAndConnection) {
+func HandleState2(api apis.Server, config *conf.ServerConfig, watcher util.Watcher, sessions *Sessions, join chan base.PlayerAndConnection) error {
+	service, err := auth.NewService(config)
+	if err != nil {
+		return err
+	}
+
+	logins := newLogins(api, func(prof game.Profile, conn base.Connection) {
+		login(config, sessions, prof, conn, join)
+	})
 
 	watcher.SubAs(func(packet *server.PacketILoginStart, conn base.Connection) {
 		playerName := packet.PlayerName
 
+		// the proxy already authenticated the player
+		if forwarded := conn.Forwarded(); forwarded != nil {
+			if !sessions.advance(conn, stepStart, stepVerifying) {
+				disconnectForwarding(conn, unexpectedPacket)
+				return
+			}
+
+			prof := *forwarded
+			prof.Name = playerName
+
+			logins.begin(prof, conn)
+			return
+		}
+
+		// the proxy answers with the player, see the login plugin response below
+		if config.Network.Forwarding == conf.VelocityForwarding {
+			if !sessions.advance(conn, stepStart, stepForwarding) {
+				disconnectForwarding(conn, unexpectedPacket)
+				return
+			}
+
+			requestVelocity(conn)
+			return
+		}
+
 		if !config.OnlineMode {
+			if !sessions.advance(conn, stepStart, stepVerifying) {
+				disconnectForwarding(conn, unexpectedPacket)
+				return
+			}
+
 			playerUuid := uuid.TextToUUID("OfflinePlayer:" + playerName)
 
 			prof := game.Profile{
@@ -35,17 +74,20 @@ func HandleState2(config *conf.ServerConfig, watcher util.Watcher, join chan bas
 				Name: playerName,
 			}
 
-			login(prof, conn, join)
+			logins.begin(prof, conn)
 			return
 		}
 
-		conn.CertifyValues(playerName)
+		if !sessions.advance(conn, stepStart, stepEncryption) {
+			disconnectForwarding(conn, unexpectedPacket)
+			return
+		}
 
-		_, public := auth.NewCrypt()
+		conn.CertifyValues(playerName)
 
 		response := client.PacketOEncryptionRequest{
 			Server: "",
-			Public: public,
+			Public: service.Public(),
 			Verify: conn.CertifyData(),
 		}
 
@@ -53,6 +95,12 @@ func HandleState2(config *conf.ServerConfig, watcher util.Watcher, join chan bas
 	})
 
 	watcher.SubAs(func(packet *server.PacketIEncryptionResponse, conn base.Connection) {
+		// only the one response to our encryption request is accepted
+		if !sessions.advance(conn, stepEncryption, stepVerifying) {
+			disconnectForwarding(conn, unexpectedPacket)
+			return
+		}
+
 		defer func() {
 			if err := recover(); err != nil {
 				conn.SendPacket(&client.PacketODisconnect{
@@ -61,7 +109,7 @@ func HandleState2(config *conf.ServerConfig, watcher util.Watcher, join chan bas
 			}
 		}()
 
-		ver, err := auth.Decrypt(packet.Verify)
+		ver, err := service.Decrypt(packet.Verify)
 		if err != nil {
 			panic(fmt.Errorf("failed to decrypt token: %s\n%v\n", conn.CertifyName(), err))
 		}
@@ -70,14 +118,14 @@ func HandleState2(config *conf.ServerConfig, watcher util.Watcher, join chan bas
 			panic(fmt.Errorf("encryption failed, tokens are different: %s\n%v | %v", conn.CertifyName(), ver, conn.CertifyData()))
 		}
 
-		sec, err := auth.Decrypt(packet.Secret)
+		sec, err := service.Decrypt(packet.Secret)
 		if err != nil {
 			panic(fmt.Errorf("failed to decrypt secret: %s\n%v\n", conn.CertifyName(), err))
 		}
 
 		conn.CertifyUpdate(sec) // enable encryption on the connection
 
-		auth.RunAuthGet(sec, conn.CertifyName(), func(auth *auth.Auth, err error) {
+		service.RunAuthGet(sec, conn.CertifyName(), conn.Address(), func(auth *auth.Auth, err error) {
 			defer func() {
 				if err := recover(); err != nil {
 					conn.SendPacket(&client.PacketODisconnect{
@@ -105,35 +153,114 @@ func HandleState2(config *conf.ServerConfig, watcher util.Watcher, join chan bas
 				})
 			}
 
-			login(prof, conn, join)
+			logins.begin(prof, conn)
 		})
 
 	})
 
-}
+	watcher.SubAs(func(packet *server.PacketILoginPluginResponse, conn base.Connection) {
+		if logins.answer(packet, conn) {
+			return
+		}
 
-func login(prof game.Profile, conn base.Connection, join chan base.PlayerAndConnection) {
-	s := apis.MinecraftServer()
+		if config.Network.Forwarding != conf.VelocityForwarding || packet.Message != velocityMessageID {
+			return
+		}
 
-	p := s.PlayerByUUID(prof.UUID)
+		if !sessions.advance(conn, stepForwarding, stepVerifying) {
+			disconnectForwarding(conn, unexpectedPacket)
+			return
+		}
 
-	if p != nil {
-		conn.SendPacket(&client.PacketODisconnect{
-			Reason: *msgs.New(fmt.Sprintf("Player with name \"%s\" already play on the server.", p.Name())).SetColor(chat.Red),
-		})
+		if forwardVelocity(config.Network.ForwardingSecret, packet, conn) {
+			logins.begin(*conn.Forwarded(), conn)
+		}
+	})
+
+	return nil
+}
+
+func login(config *conf.ServerConfig, sessions *Sessions, prof game.Profile, conn base.Connection, join chan base.PlayerAndConnection) {
+	// the step moves before anything is sent, a connection finishes its login once
+	if !sessions.advance(conn, stepVerifying, stepFinishing) {
+		_ = conn.Stop()
+		return
+	}
+
+	if !takeOver(config, sessions, prof, conn) {
+		return
 	}
 
 	player := ents.NewPlayer(&prof, conn)
 
+	if threshold := config.Network.CompressionThreshold; threshold >= 0 {
+		conn.SendPacket(&client.PacketOSetCompression{Threshold: threshold})
+		conn.CompactUpdate(threshold) // every packet after set compression uses the compressed format
+	}
+
 	conn.SendPacket(&client.PacketOLoginSuccess{
 		PlayerName: player.Name(),
 		PlayerUUID: player.UUID().String(),
 	})
 
-	conn.SetState(base.PLAY)
+	if err := conn.SetState(base.PLAY); err != nil {
+		_ = conn.Stop()
+		return
+	}
 
 	join <- base.PlayerAndConnection{
 		Player:     player,
 		Connection: conn,
 	}
 }
+
+// the message for login packets sent out of order
+const unexpectedPacket = "Unexpected login packet"
+
+// the messages vanilla shows either side of a duplicate login
+const (
+	duplicateKicked   = "You logged in from another location"
+	duplicateRejected = "You are already logged in from another location"
+)
+
+// how long a kicked session may take to quit before the new one is refused
+const duplicateTimeout = 5 * time.Second
+
+// takeOver claims the uuid for a player logging in while they are online, false if the new connection was refused
+func takeOver(config *conf.ServerConfig, sessions *Sessions, prof game.Profile, conn base.Connection) bool {
+	deadline := time.After(duplicateTimeout)
+
+	for {
+		held, ok := sessions.claim(prof.UUID, conn)
+
+		if ok {
+			// a connection that quit before the claim is never released by its quit
+			if conn.Closed() {
+				sessions.release(conn)
+				return false
+			}
+
+			return true
+		}
+
+		if config.RejectDuplicateLogins {
+			disconnectForwarding(conn, duplicateRejected)
+			return false
+		}
+
+		// the old session may still be logging in itself
+		if held.conn.GetState() == base.PLAY {
+			kick(held.conn, *msgs.New(duplicateKicked))
+		} else {
+			disconnectForwarding(held.conn, duplicateKicked)
+		}
+
+		// the uuid is free once the old player quit, another login may claim it first
+		select {
+		case <-held.gone:
+		case <-deadline:
+			disconnectForwarding(conn, duplicateRejected)
+			return false
+		}
+	}
+}
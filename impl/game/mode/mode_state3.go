@@ -15,6 +15,7 @@ import (
 	"github.com/golangmc/minecraft-server/apis/task"
 	"github.com/golangmc/minecraft-server/apis/util"
 	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conf"
 	"github.com/golangmc/minecraft-server/impl/data/client"
 	"github.com/golangmc/minecraft-server/impl/data/plugin"
 	"github.com/golangmc/minecraft-server/impl/data/values"
@@ -24,30 +25,38 @@ import (
 	server_packet "github.com/golangmc/minecraft-server/impl/prot/server"
 )
 
-func HandleState3(watcher util.Watcher, logger *logs.Logging, tasking *task.Tasking, join chan base.PlayerAndConnection, quit chan base.PlayerAndConnection) {
+func HandleState3(api apis.Server, config *conf.ServerConfig, watcher util.Watcher, sessions *Sessions, logger *logs.Logging, tasking *task.Tasking, join chan base.PlayerAndConnection, quit chan base.PlayerAndConnection) {
 
-	tasking.EveryTime(10, time.Second, func(task *task.Task) {
+	alive := newKeepAlives()
 
-		api := apis.MinecraftServer()
+	interval := time.Duration(config.Network.KeepAliveInterval) * time.Second
+	timeout := time.Duration(config.Network.KeepAliveTimeout) * time.Second
 
-		// I hate this, add a functional method for player iterating
-		for _, player := range api.Players() {
-
-			// also probably add one that returns both the player and their connection
-			conn := api.ConnByUUID(player.UUID())
+	tasking.EveryTime(1, time.Second, func(task *task.Task) {
+		alive.tick(api.Players(), api.ConnByUUID, interval, timeout, time.Now(), logger)
+	})
 
-			// keep player connection alive via keep alive
-			conn.SendPacket(&client_packet.PacketOKeepAlive{KeepAliveID: time.Now().UnixNano() / 1e6})
-		}
+	// the tab list of every player is updated with the pings of all players
+	tasking.EveryTime(30, time.Second, func(task *task.Task) {
+		sendLatency(api.Players(), api.ConnByUUID)
 	})
 
 	watcher.SubAs(func(packet *server_packet.PacketIKeepAlive, conn base.Connection) {
-		logger.DataF("player %s is being kept alive", conn.Address())
+		rtt, ok := alive.answer(conn, packet.KeepAliveID, time.Now())
+
+		if !ok {
+			logger.WarnF("disconnecting %v, they answered an unknown keep alive %d", conn.Address(), packet.KeepAliveID)
+
+			kick(conn, *msgs.NewTranslate("disconnect.timeout"))
+			return
+		}
+
+		if player := api.PlayerByConn(conn); player != nil {
+			player.SetPing(smoothPing(player.GetPing(), rtt))
+		}
 	})
 
 	watcher.SubAs(func(packet *server_packet.PacketIPluginMessage, conn base.Connection) {
-		api := apis.MinecraftServer()
-
 		player := api.PlayerByConn(conn)
 		if player == nil {
 			return // log no player found?
@@ -64,8 +73,6 @@ func HandleState3(watcher util.Watcher, logger *logs.Logging, tasking *task.Task
 	})
 
 	watcher.SubAs(func(packet *server_packet.PacketIChatMessage, conn base.Connection) {
-		api := apis.MinecraftServer()
-
 		who := api.PlayerByConn(conn)
 
 		text := packet.Message
@@ -110,7 +117,6 @@ func HandleState3(watcher util.Watcher, logger *logs.Logging, tasking *task.Task
 	})
 
 	watcher.SubAs(func(packet *server_packet.PacketIPlayerLocation, conn base.Connection) {
-		api := apis.MinecraftServer()
 		who := api.PlayerByConn(conn)
 
 		who.SetLocation(packet.Location)
@@ -119,13 +125,14 @@ func HandleState3(watcher util.Watcher, logger *logs.Logging, tasking *task.Task
 	go func() {
 		for conn := range join {
 
-			apis.MinecraftServer().Watcher().PubAs(impl_event.PlayerConnJoinEvent{Conn: conn})
+			api.Watcher().PubAs(impl_event.PlayerConnJoinEvent{Conn: conn})
 
 			conn.SendPacket(&client_packet.PacketOJoinGame{
 				EntityID:      int32(conn.EntityUUID()),
 				Hardcore:      false,
 				GameMode:      game.CREATIVE,
 				Dimension:     game.OVERWORLD,
+				Difficulty:    game.PEACEFUL,
 				HashedSeed:    values.DefaultWorldHashedSeed,
 				MaxPlayers:    10,
 				LevelType:     game.DEFAULT,
@@ -136,7 +143,7 @@ func HandleState3(watcher util.Watcher, logger *logs.Logging, tasking *task.Task
 
 			conn.SendPacket(&client_packet.PacketOPluginMessage{
 				Message: &plugin.Brand{
-					Name: chat.Translate(fmt.Sprintf("&c&l%s&r &a%s&r", "LoperMC", apis.MinecraftServer().ServerVersion())),
+					Name: chat.Translate(fmt.Sprintf("&c&l%s&r &a%s&r", "LoperMC", api.ServerVersion())),
 				},
 			})
 
@@ -181,13 +188,28 @@ func HandleState3(watcher util.Watcher, logger *logs.Logging, tasking *task.Task
 			conn.SendPacket(&client_packet.PacketOPlayerInfo{
 				Action: client.AddPlayer,
 				Values: []client.PlayerInfo{
-					&client.PlayerInfoAddPlayer{Player: conn.Player},
+					&client.PlayerInfoAddPlayer{
+						Profile:  conn.Player.GetProfile(),
+						GameMode: conn.Player.GetGameMode(),
+						Latency:  conn.Player.GetPing(),
+					},
 				},
 			})
 
-			conn.SendPacket(&client_packet.PacketOEntityMetadata{Entity: conn.Player})
+			conn.SendPacket(&client_packet.PacketOEntityMetadata{
+				EntityID: int32(conn.Player.EntityUUID()),
+				SkinParts: &client.SkinParts{
+					Cape: true,
+					Head: true,
+					Body: true,
+					ArmL: true,
+					ArmR: true,
+					LegL: true,
+					LegR: true,
+				},
+			})
 
-			for _, chunk := range apis.MinecraftServer().GetLevel().Chunks() {
+			for _, chunk := range api.GetLevel().Chunks() {
 				conn.SendPacket(&client_packet.PacketOChunkData{Chunk: chunk})
 			}
 
@@ -213,7 +235,12 @@ func HandleState3(watcher util.Watcher, logger *logs.Logging, tasking *task.Task
 
 	go func() {
 		for conn := range quit {
-			apis.MinecraftServer().Watcher().PubAs(impl_event.PlayerConnQuitEvent{Conn: conn})
+			alive.remove(conn.Connection)
+
+			api.Watcher().PubAs(impl_event.PlayerConnQuitEvent{Conn: conn})
+
+			// the player is removed, a login waiting for the uuid may go on
+			sessions.release(conn.Connection)
 		}
 	}()
 }
@@ -0,0 +1,90 @@
+package mode
+
+import (
+	"sync"
+
+	"github.com/golangmc/minecraft-server/apis/uuid"
+	"github.com/golangmc/minecraft-server/impl/base"
+)
+
+// session is the connection logged in with a uuid
+type session struct {
+	conn base.Connection
+	gone chan struct{} // closed once the connection quit and its player was removed
+}
+
+// loginStep is how far a connection got logging in, packets sent out of order disconnect it
+type loginStep int
+
+const (
+	stepStart      loginStep = iota // waiting for login start
+	stepEncryption                  // waiting for the encryption response
+	stepForwarding                  // waiting for the proxy to forward the player
+	stepVerifying                   // the player is authenticated and the login event runs
+	stepFinishing                   // login success was sent, no other login packet is expected
+)
+
+// Sessions hands each uuid to one connection at a time, logins for a uuid someone holds wait for them to quit
+type Sessions struct {
+	lock  sync.Mutex
+	uuids map[uuid.UUID]*session
+	conns map[base.Connection]uuid.UUID
+	steps map[base.Connection]loginStep
+}
+
+func NewSessions() *Sessions {
+	return &Sessions{
+		uuids: make(map[uuid.UUID]*session),
+		conns: make(map[base.Connection]uuid.UUID),
+		steps: make(map[base.Connection]loginStep),
+	}
+}
+
+// advance moves the connection to the next login step, false if it is not at the step expected
+func (s *Sessions) advance(conn base.Connection, from loginStep, to loginStep) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.steps[conn] != from {
+		return false
+	}
+
+	s.steps[conn] = to
+
+	return true
+}
+
+// claim gives the uuid to conn if nobody holds it, otherwise it returns the session holding it
+func (s *Sessions) claim(id uuid.UUID, conn base.Connection) (held *session, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if held := s.uuids[id]; held != nil {
+		return held, false
+	}
+
+	s.uuids[id] = &session{conn: conn, gone: make(chan struct{})}
+	s.conns[conn] = id
+
+	return nil, true
+}
+
+// release frees the uuid the connection held, waking the logins waiting for it
+func (s *Sessions) release(conn base.Connection) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.steps, conn)
+
+	id, ok := s.conns[conn]
+	if !ok {
+		return
+	}
+
+	delete(s.conns, conn)
+
+	held := s.uuids[id]
+	delete(s.uuids, id)
+
+	close(held.gone)
+}
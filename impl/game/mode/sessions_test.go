@@ -0,0 +1,57 @@
+package mode
+
+import (
+	"testing"
+
+	"github.com/golangmc/minecraft-server/apis/uuid"
+)
+
+func TestSessions(t *testing.T) {
+	sessions := NewSessions()
+	id := uuid.TextToUUID("OfflinePlayer:sessions")
+
+	old, other := &tickConn{}, &tickConn{}
+
+	if _, ok := sessions.claim(id, old); !ok {
+		t.Fatal("expected a free uuid to be claimed")
+	}
+
+	held, ok := sessions.claim(id, other)
+	if ok || held.conn != old {
+		t.Fatal("expected the uuid to stay with the first connection")
+	}
+
+	sessions.release(other) // holds nothing, nothing happens
+
+	select {
+	case <-held.gone:
+		t.Fatal("expected the session to live until its connection quit")
+	default:
+	}
+
+	sessions.release(old)
+	sessions.release(old)
+
+	<-held.gone
+
+	if _, ok := sessions.claim(id, other); !ok {
+		t.Fatal("expected the released uuid to be claimed")
+	}
+}
+
+func TestSessions_Advance(t *testing.T) {
+	sessions := NewSessions()
+	conn := &tickConn{}
+
+	if sessions.advance(conn, stepEncryption, stepVerifying) {
+		t.Fatal("expected an encryption response before login start to be refused")
+	}
+
+	if !sessions.advance(conn, stepStart, stepEncryption) || !sessions.advance(conn, stepEncryption, stepVerifying) {
+		t.Fatal("expected the login steps in order to be accepted")
+	}
+
+	if sessions.advance(conn, stepEncryption, stepVerifying) {
+		t.Fatal("expected a repeated encryption response to be refused")
+	}
+}
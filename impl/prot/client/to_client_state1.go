@@ -26,6 +26,16 @@ func (p *PacketOResponse) Push(writer buff.Buffer, conn base.Connection) {
 	}
 }
 
+func (p *PacketOResponse) Pull(reader buff.Buffer, conn base.Connection) error {
+	text := reader.PullTxt()
+
+	if err := reader.Err(); err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(text), &p.Status)
+}
+
 type PacketOPong struct {
 	Ping int64
 }
@@ -37,3 +47,9 @@ func (p *PacketOPong) UUID() int32 {
 func (p *PacketOPong) Push(writer buff.Buffer, conn base.Connection) {
 	writer.PushI64(p.Ping)
 }
+
+func (p *PacketOPong) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Ping = reader.PullI64()
+
+	return reader.Err()
+}
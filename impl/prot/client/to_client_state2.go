@@ -22,6 +22,12 @@ func (p *PacketODisconnect) Push(writer buff.Buffer, conn base.Connection) {
 	writer.PushTxt(message.AsJson())
 }
 
+func (p *PacketODisconnect) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Reason = *msgs.OfJson(reader.PullTxt())
+
+	return reader.Err()
+}
+
 type PacketOEncryptionRequest struct {
 	Server string // unused?
 	Public []byte
@@ -38,6 +44,14 @@ func (p *PacketOEncryptionRequest) Push(writer buff.Buffer, conn base.Connection
 	writer.PushUAS(p.Verify, true)
 }
 
+func (p *PacketOEncryptionRequest) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Server = reader.PullTxt()
+	p.Public = reader.PullUAS()
+	p.Verify = reader.PullUAS()
+
+	return reader.Err()
+}
+
 type PacketOLoginSuccess struct {
 	PlayerUUID string
 	PlayerName string
@@ -52,6 +66,13 @@ func (p *PacketOLoginSuccess) Push(writer buff.Buffer, conn base.Connection) {
 	writer.PushTxt(p.PlayerName)
 }
 
+func (p *PacketOLoginSuccess) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.PlayerUUID = reader.PullTxt()
+	p.PlayerName = reader.PullTxt()
+
+	return reader.Err()
+}
+
 type PacketOSetCompression struct {
 	Threshold int32
 }
@@ -64,6 +85,12 @@ func (p *PacketOSetCompression) Push(writer buff.Buffer, conn base.Connection) {
 	writer.PushVrI(p.Threshold)
 }
 
+func (p *PacketOSetCompression) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Threshold = reader.PullVrI()
+
+	return reader.Err()
+}
+
 type PacketOLoginPluginRequest struct {
 	MessageID int32
 	Channel   string
@@ -79,3 +106,21 @@ func (p *PacketOLoginPluginRequest) Push(writer buff.Buffer, conn base.Connectio
 	writer.PushTxt(p.Channel)
 	writer.PushUAS(p.OptData, false)
 }
+
+func (p *PacketOLoginPluginRequest) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.MessageID = reader.PullVrI()
+	p.Channel = reader.PullTxt()
+	p.OptData = pullRest(reader)
+
+	return reader.Err()
+}
+
+// pullRest returns the bytes left in the reader, for fields that take up the remainder of a packet
+func pullRest(reader buff.Buffer) []byte {
+	rest := make([]byte, reader.Len()-reader.InI())
+	copy(rest, reader.UAS()[reader.InI():])
+
+	reader.SkpLen(int32(len(rest)))
+
+	return rest
+}
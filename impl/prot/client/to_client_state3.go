@@ -1,10 +1,12 @@
 package client
 
 import (
+	"fmt"
+
 	"github.com/golangmc/minecraft-server/apis/buff"
+	"github.com/golangmc/minecraft-server/apis/chns"
 	"github.com/golangmc/minecraft-server/apis/data"
 	"github.com/golangmc/minecraft-server/apis/data/msgs"
-	"github.com/golangmc/minecraft-server/apis/ents"
 	"github.com/golangmc/minecraft-server/apis/game"
 	"github.com/golangmc/minecraft-server/apis/game/level"
 	"github.com/golangmc/minecraft-server/impl/base"
@@ -34,11 +36,39 @@ func (p *PacketOChatMessage) Push(writer buff.Buffer, conn base.Connection) {
 	writer.PushByt(byte(p.MessagePosition))
 }
 
+func (p *PacketOChatMessage) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Message = *msgs.OfJson(reader.PullTxt())
+	p.MessagePosition = msgs.MessagePosition(reader.PullByt())
+
+	return reader.Err()
+}
+
+type PacketOPlayDisconnect struct {
+	Reason msgs.Message
+}
+
+func (p *PacketOPlayDisconnect) UUID() int32 {
+	return 0x1B
+}
+
+func (p *PacketOPlayDisconnect) Push(writer buff.Buffer, conn base.Connection) {
+	message := p.Reason
+
+	writer.PushTxt(message.AsJson())
+}
+
+func (p *PacketOPlayDisconnect) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Reason = *msgs.OfJson(reader.PullTxt())
+
+	return reader.Err()
+}
+
 type PacketOJoinGame struct {
 	EntityID      int32
 	Hardcore      bool
 	GameMode      game.GameMode
 	Dimension     game.Dimension
+	Difficulty    game.Difficulty // only sent before 1.14
 	HashedSeed    int64
 	MaxPlayers    int
 	LevelType     game.LevelType
@@ -52,15 +82,67 @@ func (p *PacketOJoinGame) UUID() int32 {
 }
 
 func (p *PacketOJoinGame) Push(writer buff.Buffer, conn base.Connection) {
+	version := conn.GetVersion()
+
 	writer.PushI32(p.EntityID)
 	writer.PushByt(p.GameMode.Encoded(p.Hardcore /* pull this value from somewhere */))
 	writer.PushI32(int32(p.Dimension))
-	writer.PushI64(p.HashedSeed)
+
+	if version >= data.MC1_15_2 {
+		writer.PushI64(p.HashedSeed)
+	}
+
+	if version < data.MC1_14_4 {
+		writer.PushByt(byte(p.Difficulty))
+	}
+
 	writer.PushByt(uint8(p.MaxPlayers))
 	writer.PushTxt(p.LevelType.String())
-	writer.PushVrI(p.ViewDistance)
+
+	if version >= data.MC1_14_4 {
+		writer.PushVrI(p.ViewDistance)
+	}
+
 	writer.PushBit(p.ReduceDebug)
-	writer.PushBit(p.RespawnScreen)
+
+	if version >= data.MC1_15_2 {
+		writer.PushBit(p.RespawnScreen)
+	}
+}
+
+func (p *PacketOJoinGame) Pull(reader buff.Buffer, conn base.Connection) error {
+	version := conn.GetVersion()
+
+	p.EntityID = reader.PullI32()
+
+	mode := reader.PullByt()
+	p.GameMode = game.GameMode(mode & 0x7)
+	p.Hardcore = mode&0x8 != 0
+
+	p.Dimension = game.Dimension(reader.PullI32())
+
+	if version >= data.MC1_15_2 {
+		p.HashedSeed = reader.PullI64()
+	}
+
+	if version < data.MC1_14_4 {
+		p.Difficulty = game.Difficulty(reader.PullByt())
+	}
+
+	p.MaxPlayers = int(reader.PullByt())
+	p.LevelType = game.LevelTypeValueOf(reader.PullTxt())
+
+	if version >= data.MC1_14_4 {
+		p.ViewDistance = reader.PullVrI()
+	}
+
+	p.ReduceDebug = reader.PullBit()
+
+	if version >= data.MC1_15_2 {
+		p.RespawnScreen = reader.PullBit()
+	}
+
+	return reader.Err()
 }
 
 type PacketOPluginMessage struct {
@@ -76,6 +158,22 @@ func (p *PacketOPluginMessage) Push(writer buff.Buffer, conn base.Connection) {
 	p.Message.Push(writer)
 }
 
+func (p *PacketOPluginMessage) Pull(reader buff.Buffer, conn base.Connection) error {
+	channel := reader.PullTxt()
+
+	message := plugin.GetMessageForChannel(channel)
+
+	if message == nil {
+		message = &chns.Raw{Channel: channel} // unregistered channel
+	}
+
+	message.Pull(reader)
+
+	p.Message = message
+
+	return reader.Err()
+}
+
 type PacketOPlayerLocation struct {
 	Location data.Location
 	Relative client.Relativity
@@ -100,6 +198,21 @@ func (p *PacketOPlayerLocation) Push(writer buff.Buffer, conn base.Connection) {
 	writer.PushVrI(p.ID)
 }
 
+func (p *PacketOPlayerLocation) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Location.X = reader.PullF64()
+	p.Location.Y = reader.PullF64()
+	p.Location.Z = reader.PullF64()
+
+	p.Location.AxisX = reader.PullF32()
+	p.Location.AxisY = reader.PullF32()
+
+	p.Relative.Pull(reader)
+
+	p.ID = reader.PullVrI()
+
+	return reader.Err()
+}
+
 type PacketOKeepAlive struct {
 	KeepAliveID int64
 }
@@ -112,6 +225,12 @@ func (p *PacketOKeepAlive) Push(writer buff.Buffer, conn base.Connection) {
 	writer.PushI64(p.KeepAliveID)
 }
 
+func (p *PacketOKeepAlive) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.KeepAliveID = reader.PullI64()
+
+	return reader.Err()
+}
+
 type PacketOServerDifficulty struct {
 	Difficulty game.Difficulty
 	Locked     bool // should probably always be true
@@ -123,7 +242,20 @@ func (p *PacketOServerDifficulty) UUID() int32 {
 
 func (p *PacketOServerDifficulty) Push(writer buff.Buffer, conn base.Connection) {
 	writer.PushByt(byte(p.Difficulty))
-	writer.PushBit(p.Locked)
+
+	if conn.GetVersion() >= data.MC1_14_4 {
+		writer.PushBit(p.Locked)
+	}
+}
+
+func (p *PacketOServerDifficulty) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Difficulty = game.Difficulty(reader.PullByt())
+
+	if conn.GetVersion() >= data.MC1_14_4 {
+		p.Locked = reader.PullBit()
+	}
+
+	return reader.Err()
 }
 
 type PacketOPlayerAbilities struct {
@@ -143,6 +275,15 @@ func (p *PacketOPlayerAbilities) Push(writer buff.Buffer, conn base.Connection)
 	writer.PushF32(p.FieldOfView)
 }
 
+func (p *PacketOPlayerAbilities) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Abilities.Pull(reader)
+
+	p.FlyingSpeed = reader.PullF32()
+	p.FieldOfView = reader.PullF32()
+
+	return reader.Err()
+}
+
 type PacketOHeldItemChange struct {
 	Slot client.HotBarSlot
 }
@@ -155,6 +296,12 @@ func (p *PacketOHeldItemChange) Push(writer buff.Buffer, conn base.Connection) {
 	writer.PushByt(byte(p.Slot))
 }
 
+func (p *PacketOHeldItemChange) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Slot = client.HotBarSlot(reader.PullByt())
+
+	return reader.Err()
+}
+
 type PacketODeclareRecipes struct {
 	// Recipes []*Recipe // this doesn't exist yet ;(
 	RecipeCount int32
@@ -169,8 +316,22 @@ func (p *PacketODeclareRecipes) Push(writer buff.Buffer, conn base.Connection) {
 	// when recipes are implemented, instead of holding a recipe count, simply write the size of the slice, Recipe will implement BufferPush
 }
 
+func (p *PacketODeclareRecipes) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.RecipeCount = reader.PullVrI()
+	reader.SkpLen(reader.Len() - reader.InI()) // recipes are not decoded
+
+	return reader.Err()
+}
+
 type PacketOChunkData struct {
-	Chunk level.Chunk
+	Chunk level.Chunk `json:"-"`
+
+	// decoded when pulled, the chunk itself is not rebuilt
+	ChunkX int32
+	ChunkZ int32
+	Full   bool
+	Mask   int32
+	Data   []byte // everything after the primary bit mask, as it was sent
 }
 
 func (p *PacketOChunkData) UUID() int32 {
@@ -178,12 +339,19 @@ func (p *PacketOChunkData) UUID() int32 {
 }
 
 func (p *PacketOChunkData) Push(writer buff.Buffer, conn base.Connection) {
+	version := conn.GetVersion()
+
 	writer.PushI32(int32(p.Chunk.ChunkX()))
 	writer.PushI32(int32(p.Chunk.ChunkZ()))
 
 	// full chunk (for now >:D)
 	writer.PushBit(true)
 
+	if version < data.MC1_14_4 {
+		p.pushLegacy(writer)
+		return
+	}
+
 	chunkData := apis_conn.NewBuffer()
 	p.Chunk.Push(chunkData) // write chunk data and primary bit mask
 
@@ -193,15 +361,86 @@ func (p *PacketOChunkData) Push(writer buff.Buffer, conn base.Connection) {
 	// write height-maps
 	writer.PushNbt(p.Chunk.HeightMapNbtCompound())
 
-	biomes := make([]int32, 1024, 1024)
-	for i := range biomes {
-		biomes[i] = 0 // void biome
+	if version >= data.MC1_15_2 {
+		biomes := make([]int32, 1024, 1024)
+		for i := range biomes {
+			biomes[i] = 0 // void biome
+		}
+
+		for _, biome := range biomes {
+			writer.PushI32(biome)
+		}
+	} else {
+		// before 1.15 the biomes are a 16x16 grid at the end of the data
+		for i := 0; i < 256; i++ {
+			chunkData.PushI32(0) // void biome
+		}
 	}
 
-	for _, biome := range biomes {
-		writer.PushI32(biome)
+	// data, prefixed with len
+	writer.PushUAS(chunkData.UAS(), true)
+
+	// write block entities
+	writer.PushVrI(0)
+}
+
+func (p *PacketOChunkData) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.ChunkX = reader.PullI32()
+	p.ChunkZ = reader.PullI32()
+	p.Full = reader.PullBit()
+	p.Mask = reader.PullVrI()
+	p.Data = pullRest(reader)
+
+	return reader.Err()
+}
+
+// pushLegacy writes the 1.13 layout, which has no height-maps and carries light inside each slice
+//
+// block values are written as they are stored, they are not translated to the global palette of older versions
+func (p *PacketOChunkData) pushLegacy(writer buff.Buffer) {
+	bits := level.BitsPerBlock
+
+	mask := int32(0)
+	chunkData := apis_conn.NewBuffer()
+
+	for index, slice := range p.Chunk.Slices() {
+		mask |= 1 << index
+
+		values := base.NewCompacter(bits, level.SliceS)
+
+		for y := 0; y < level.SliceH; y++ {
+			for z := 0; z < level.ChunkL; z++ {
+				for x := 0; x < level.ChunkW; x++ {
+					values.Set(y<<0x08|z<<0x04|x, slice.GetBlock(x, y, z).GetBlockType())
+				}
+			}
+		}
+
+		chunkData.PushByt(byte(bits))
+		chunkData.PushVrI(0) // the direct palette still has a length, which is always 0
+
+		chunkData.PushVrI(int32(len(values.Values)))
+		for _, value := range values.Values {
+			chunkData.PushI64(value)
+		}
+
+		light := make([]byte, level.SliceS/2)
+
+		chunkData.PushUAS(light, false) // block light
+
+		for i := range light {
+			light[i] = 0xFF
+		}
+
+		chunkData.PushUAS(light, false) // sky light
+	}
+
+	for i := 0; i < 256; i++ {
+		chunkData.PushI32(0) // void biome
 	}
 
+	writer.PushVrI(mask)
+
 	// data, prefixed with len
 	writer.PushUAS(chunkData.UAS(), true)
 
@@ -227,8 +466,31 @@ func (p *PacketOPlayerInfo) Push(writer buff.Buffer, conn base.Connection) {
 	}
 }
 
+func (p *PacketOPlayerInfo) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Action = client.PlayerInfoAction(reader.PullVrI())
+
+	size := reader.PullVrI()
+	p.Values = make([]client.PlayerInfo, 0)
+
+	for i := int32(0); i < size && reader.Err() == nil; i++ {
+		value := client.NewPlayerInfo(p.Action)
+		if value == nil {
+			return fmt.Errorf("unknown player info action %d", p.Action)
+		}
+
+		value.Pull(reader)
+
+		p.Values = append(p.Values, value)
+	}
+
+	return reader.Err()
+}
+
 type PacketOEntityMetadata struct {
-	Entity ents.Entity
+	EntityID int32
+
+	// only supporting player metadata for now, nil if not sent
+	SkinParts *client.SkinParts
 }
 
 func (p *PacketOEntityMetadata) UUID() int32 {
@@ -236,27 +498,48 @@ func (p *PacketOEntityMetadata) UUID() int32 {
 }
 
 func (p *PacketOEntityMetadata) Push(writer buff.Buffer, conn base.Connection) {
-	writer.PushVrI(int32(p.Entity.EntityUUID())) // questionable...
-
-	// only supporting player metadata for now
-	_, ok := p.Entity.(ents.Player)
-	if ok {
-
-		writer.PushByt(16) // index | displayed skin parts
-		writer.PushVrI(0)  // type | byte
-
-		skin := client.SkinParts{
-			Cape: true,
-			Head: true,
-			Body: true,
-			ArmL: true,
-			ArmR: true,
-			LegL: true,
-			LegR: true,
-		}
+	writer.PushVrI(p.EntityID)
+
+	if p.SkinParts != nil {
+		writer.PushByt(skinPartsIndex(conn.GetVersion())) // index | displayed skin parts
+		writer.PushVrI(0)                                 // type | byte
 
-		skin.Push(writer)
+		p.SkinParts.Push(writer)
 	}
 
 	writer.PushByt(0xFF)
 }
+
+func (p *PacketOEntityMetadata) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.EntityID = reader.PullVrI()
+
+	for {
+		index := reader.PullByt()
+		if index == 0xFF {
+			break
+		}
+
+		// values of other types can't be skipped without decoding them
+		if index != skinPartsIndex(conn.GetVersion()) || reader.PullVrI() != 0 {
+			reader.SkpLen(reader.Len() - reader.InI())
+			break
+		}
+
+		p.SkinParts = &client.SkinParts{}
+		p.SkinParts.Pull(reader)
+	}
+
+	return reader.Err()
+}
+
+// the metadata index of a player's displayed skin parts moved as fields were added to living entities
+func skinPartsIndex(version data.MinecraftVersion) byte {
+	switch {
+	case version >= data.MC1_15_2:
+		return 16
+	case version >= data.MC1_14_4:
+		return 15
+	default:
+		return 13
+	}
+}
@@ -1,113 +1,209 @@
 package prot
 
 import (
+	"github.com/golangmc/minecraft-server/apis"
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/data/msgs"
 	"github.com/golangmc/minecraft-server/apis/logs"
 	"github.com/golangmc/minecraft-server/apis/task"
 	"github.com/golangmc/minecraft-server/apis/util"
 	"github.com/golangmc/minecraft-server/impl/base"
 	"github.com/golangmc/minecraft-server/impl/conf"
 	"github.com/golangmc/minecraft-server/impl/game/mode"
+	"github.com/golangmc/minecraft-server/impl/prot/client"
 	"github.com/golangmc/minecraft-server/impl/prot/server"
 )
 
 type packets struct {
 	util.Watcher
 
-	logger  *logs.Logging
-	packetI map[base.PacketState]map[int32]func() base.PacketI // UUID to I server_data
+	logger   *logs.Logging
+	registry *Registry
 
 	join chan base.PlayerAndConnection
 	quit chan base.PlayerAndConnection
 }
 
-func NewPackets(config *conf.ServerConfig, tasking *task.Tasking, join chan base.PlayerAndConnection, quit chan base.PlayerAndConnection) base.Packets {
+func NewPackets(api apis.Server, config *conf.ServerConfig, tasking *task.Tasking, join chan base.PlayerAndConnection, quit chan base.PlayerAndConnection) (base.Packets, error) {
 	packets := &packets{
 		Watcher: util.NewWatcher(),
 
-		logger:  logs.NewLogging("protocol", logs.EveryLevel...),
-		packetI: createPacketI(),
+		logger:   logs.NewLogging("protocol", logs.EveryLevel...),
+		registry: NewRegistry(),
 	}
 
-	mode.HandleState0(packets)
-	mode.HandleState1(packets)
-	mode.HandleState2(config, packets, join)
-	mode.HandleState3(packets, packets.logger, tasking, join, quit)
+	sessions := mode.NewSessions()
 
-	return packets
+	mode.HandleState0(config, packets)
+	mode.HandleState1(api, config, packets)
+	if err := mode.HandleState2(api, config, packets, sessions, join); err != nil {
+		return nil, err
+	}
+
+	mode.HandleState3(api, config, packets, sessions, packets.logger, tasking, join, quit)
+
+	return packets, nil
+}
+
+func (p *packets) GetPacketM(uuid int32, state base.PacketState, version data.MinecraftVersion) (pid int32, cont bool) {
+	return p.registry.Map(base.CLIENTBOUND, state, version, uuid)
 }
 
-func (p *packets) GetPacketI(uuid int32, state base.PacketState) base.PacketI {
-	creator := p.packetI[state][uuid]
-	if creator == nil {
-		return nil
+func (p *packets) GetPacketI(uuid int32, state base.PacketState, version data.MinecraftVersion) base.PacketI {
+	packet, _ := p.registry.Create(base.SERVERBOUND, state, version, uuid).(base.PacketI)
+	return packet
+}
+
+func (p *packets) GetPacketO(uuid int32, state base.PacketState, version data.MinecraftVersion) base.PacketO {
+	packet, _ := p.registry.Create(base.CLIENTBOUND, state, version, uuid).(base.PacketO)
+	return packet
+}
+
+func (p *packets) Disconnect(conn base.Connection, reason msgs.Message) {
+	switch conn.GetState() {
+	case base.LOGIN:
+		conn.SendPacket(&client.PacketODisconnect{Reason: reason})
+	case base.PLAY:
+		conn.SendPacket(&client.PacketOPlayDisconnect{Reason: reason})
 	}
 
-	return creator()
+	_ = conn.Stop()
 }
 
-func createPacketI() map[base.PacketState]map[int32]func() base.PacketI {
-	return map[base.PacketState]map[int32]func() base.PacketI{
+func createPacketI() map[base.PacketState]map[int32]func() base.Packet {
+	return map[base.PacketState]map[int32]func() base.Packet{
 		base.SHAKE: {
-			0x00: func() base.PacketI {
+			0x00: func() base.Packet {
 				return &server.PacketIHandshake{}
 			},
 		},
 		base.STATUS: {
-			0x00: func() base.PacketI {
+			0x00: func() base.Packet {
 				return &server.PacketIRequest{}
 			},
-			0x01: func() base.PacketI {
+			0x01: func() base.Packet {
 				return &server.PacketIPing{}
 			},
 		},
 		base.LOGIN: {
-			0x00: func() base.PacketI {
+			0x00: func() base.Packet {
 				return &server.PacketILoginStart{}
 			},
-			0x01: func() base.PacketI {
+			0x01: func() base.Packet {
 				return &server.PacketIEncryptionResponse{}
 			},
-			0x02: func() base.PacketI {
+			0x02: func() base.Packet {
 				return &server.PacketILoginPluginResponse{}
 			},
 		},
 		base.PLAY: {
-			0x00: func() base.PacketI {
+			0x00: func() base.Packet {
 				return &server.PacketITeleportConfirm{}
 			},
-			0x01: func() base.PacketI {
+			0x01: func() base.Packet {
 				return &server.PacketIQueryBlockNBT{}
 			},
-			0x02: func() base.PacketI {
+			0x02: func() base.Packet {
 				return &server.PacketISetDifficulty{}
 			},
-			0x03: func() base.PacketI {
+			0x03: func() base.Packet {
 				return &server.PacketIChatMessage{}
 			},
-			0x04: func() base.PacketI {
+			0x04: func() base.Packet {
 				return &server.PacketIClientStatus{}
 			},
-			0x05: func() base.PacketI {
+			0x05: func() base.Packet {
 				return &server.PacketIClientSettings{}
 			},
-			0x0B: func() base.PacketI {
+			0x0B: func() base.Packet {
 				return &server.PacketIPluginMessage{}
 			},
-			0x0F: func() base.PacketI {
+			0x0F: func() base.Packet {
 				return &server.PacketIKeepAlive{}
 			},
-			0x11: func() base.PacketI {
+			0x11: func() base.Packet {
 				return &server.PacketIPlayerPosition{}
 			},
-			0x12: func() base.PacketI {
+			0x12: func() base.Packet {
 				return &server.PacketIPlayerLocation{}
 			},
-			0x13: func() base.PacketI {
+			0x13: func() base.Packet {
 				return &server.PacketIPlayerRotation{}
 			},
-			0x19: func() base.PacketI {
+			0x19: func() base.Packet {
 				return &server.PacketIPlayerAbilities{}
 			},
 		},
 	}
 }
+
+func createPacketO() map[base.PacketState]map[int32]func() base.Packet {
+	return map[base.PacketState]map[int32]func() base.Packet{
+		base.STATUS: {
+			0x00: func() base.Packet {
+				return &client.PacketOResponse{}
+			},
+			0x01: func() base.Packet {
+				return &client.PacketOPong{}
+			},
+		},
+		base.LOGIN: {
+			0x00: func() base.Packet {
+				return &client.PacketODisconnect{}
+			},
+			0x01: func() base.Packet {
+				return &client.PacketOEncryptionRequest{}
+			},
+			0x02: func() base.Packet {
+				return &client.PacketOLoginSuccess{}
+			},
+			0x03: func() base.Packet {
+				return &client.PacketOSetCompression{}
+			},
+			0x04: func() base.Packet {
+				return &client.PacketOLoginPluginRequest{}
+			},
+		},
+		base.PLAY: {
+			0x0E: func() base.Packet {
+				return &client.PacketOServerDifficulty{}
+			},
+			0x0F: func() base.Packet {
+				return &client.PacketOChatMessage{}
+			},
+			0x19: func() base.Packet {
+				return &client.PacketOPluginMessage{}
+			},
+			0x1B: func() base.Packet {
+				return &client.PacketOPlayDisconnect{}
+			},
+			0x21: func() base.Packet {
+				return &client.PacketOKeepAlive{}
+			},
+			0x22: func() base.Packet {
+				return &client.PacketOChunkData{}
+			},
+			0x26: func() base.Packet {
+				return &client.PacketOJoinGame{}
+			},
+			0x32: func() base.Packet {
+				return &client.PacketOPlayerAbilities{}
+			},
+			0x34: func() base.Packet {
+				return &client.PacketOPlayerInfo{}
+			},
+			0x36: func() base.Packet {
+				return &client.PacketOPlayerLocation{}
+			},
+			0x40: func() base.Packet {
+				return &client.PacketOHeldItemChange{}
+			},
+			0x44: func() base.Packet {
+				return &client.PacketOEntityMetadata{}
+			},
+			0x5B: func() base.Packet {
+				return &client.PacketODeclareRecipes{}
+			},
+		},
+	}
+}
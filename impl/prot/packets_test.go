@@ -0,0 +1,66 @@
+package prot
+
+import (
+	"testing"
+
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/conn"
+)
+
+// fuzzConnection only answers what decoding asks of a connection
+type fuzzConnection struct {
+	base.Connection
+
+	state   base.PacketState
+	version data.MinecraftVersion
+}
+
+func (c *fuzzConnection) GetState() base.PacketState {
+	return c.state
+}
+
+func (c *fuzzConnection) GetVersion() data.MinecraftVersion {
+	return c.version
+}
+
+// FuzzPacketI decodes arbitrary frames as every serverbound packet, run with go test -fuzz=FuzzPacketI ./impl/prot
+func FuzzPacketI(f *testing.F) {
+	registry := NewRegistry()
+
+	for state, packets := range createPacketI() {
+		for uuid := range packets {
+			for index, version := range data.SupportedVersions {
+				pid, cont := registry.Map(base.SERVERBOUND, state, version, uuid)
+				if !cont {
+					continue
+				}
+
+				f.Add(byte(state), byte(index), []byte{byte(pid)})
+				f.Add(byte(state), byte(index), []byte{byte(pid), 0xFF, 0xFF, 0xFF, 0xFF, 0x0F, 0x00})
+			}
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, state byte, version byte, frame []byte) {
+		c := &fuzzConnection{
+			state:   base.PacketState(state % 4),
+			version: data.SupportedVersions[int(version)%len(data.SupportedVersions)],
+		}
+
+		reader := conn.NewBufferWith(frame)
+
+		uuid := reader.PullVrI()
+		if reader.Err() != nil {
+			return
+		}
+
+		packet, _ := registry.Create(base.SERVERBOUND, c.state, c.version, uuid).(base.PacketI)
+		if packet == nil {
+			return
+		}
+
+		// errors are expected, only panics fail
+		_ = packet.Pull(reader, c)
+	})
+}
@@ -0,0 +1,157 @@
+package prot
+
+import (
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/impl/base"
+)
+
+// current packet uuid to the id the packet has in an older version, states that are missing are unchanged
+type packetIDs map[base.PacketState]map[int32]int32
+
+// packets sent by the client, keyed by version, 1.14.4 uses the same ids as the current version
+var versionI = map[data.MinecraftVersion]packetIDs{
+	data.MC1_13_2: {
+		base.PLAY: {
+			0x00: 0x00, // teleport confirm
+			0x01: 0x01, // query block nbt
+			0x03: 0x02, // chat message
+			0x04: 0x03, // client status
+			0x05: 0x04, // client settings
+			0x0B: 0x0A, // plugin message
+			0x0F: 0x0E, // keep alive
+			0x11: 0x10, // player position
+			0x12: 0x11, // player location
+			0x13: 0x12, // player rotation
+			0x19: 0x17, // player abilities
+		},
+	},
+}
+
+// packets sent by the server, keyed by version
+var versionO = map[data.MinecraftVersion]packetIDs{
+	data.MC1_14_4: {
+		base.PLAY: {
+			0x0E: 0x0D, // server difficulty
+			0x0F: 0x0E, // chat message
+			0x19: 0x18, // plugin message
+			0x1B: 0x1A, // disconnect
+			0x21: 0x20, // keep alive
+			0x22: 0x21, // chunk data
+			0x26: 0x25, // join game
+			0x32: 0x31, // player abilities
+			0x34: 0x33, // player info
+			0x36: 0x35, // player location
+			0x40: 0x3F, // held item change
+			0x44: 0x43, // entity metadata
+			0x5B: 0x5A, // declare recipes
+		},
+	},
+	data.MC1_13_2: {
+		base.PLAY: {
+			0x0E: 0x0D,
+			0x0F: 0x0E,
+			0x19: 0x19,
+			0x1B: 0x1B,
+			0x21: 0x21,
+			0x22: 0x22,
+			0x26: 0x25,
+			0x32: 0x2E,
+			0x34: 0x30,
+			0x36: 0x32,
+			0x40: 0x3D,
+			0x44: 0x3F,
+			0x5B: 0x54,
+		},
+	},
+}
+
+// Registry maps the packets of every state, direction and version to their ids and constructors
+type Registry struct {
+	// current uuid to constructor
+	create map[base.PacketDirection]map[base.PacketState]map[int32]func() base.Packet
+
+	// current uuid to version id, and back
+	mapped map[base.PacketDirection]map[data.MinecraftVersion]packetIDs
+	unmapd map[base.PacketDirection]map[data.MinecraftVersion]packetIDs
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		create: map[base.PacketDirection]map[base.PacketState]map[int32]func() base.Packet{
+			base.SERVERBOUND: createPacketI(),
+			base.CLIENTBOUND: createPacketO(),
+		},
+		mapped: map[base.PacketDirection]map[data.MinecraftVersion]packetIDs{
+			base.SERVERBOUND: versionI,
+			base.CLIENTBOUND: versionO,
+		},
+		unmapd: map[base.PacketDirection]map[data.MinecraftVersion]packetIDs{
+			base.SERVERBOUND: reverseIDs(versionI),
+			base.CLIENTBOUND: reverseIDs(versionO),
+		},
+	}
+}
+
+// Map returns the id a packet has in the version, cont is false if the version lacks the packet
+func (r *Registry) Map(direction base.PacketDirection, state base.PacketState, version data.MinecraftVersion, uuid int32) (pid int32, cont bool) {
+	if _, cont = r.create[direction][state][uuid]; !cont {
+		return 0, false
+	}
+
+	return mapPacketID(r.mapped[direction], uuid, state, version)
+}
+
+// Unmap returns the current uuid of the packet with the id in the version
+func (r *Registry) Unmap(direction base.PacketDirection, state base.PacketState, version data.MinecraftVersion, pid int32) (uuid int32, cont bool) {
+	if uuid, cont = mapPacketID(r.unmapd[direction], pid, state, version); !cont {
+		return 0, false
+	}
+
+	_, cont = r.create[direction][state][uuid]
+
+	return
+}
+
+// Create returns a new packet for the id in the version, or nil if there is none
+func (r *Registry) Create(direction base.PacketDirection, state base.PacketState, version data.MinecraftVersion, pid int32) base.Packet {
+	uuid, cont := r.Unmap(direction, state, version, pid)
+	if !cont {
+		return nil
+	}
+
+	return r.create[direction][state][uuid]()
+}
+
+func mapPacketID(mapping map[data.MinecraftVersion]packetIDs, id int32, state base.PacketState, version data.MinecraftVersion) (int32, bool) {
+	states, cont := mapping[version]
+	if !cont {
+		return id, true
+	}
+
+	ids, cont := states[state]
+	if !cont {
+		return id, true
+	}
+
+	id, cont = ids[id]
+
+	return id, cont
+}
+
+func reverseIDs(mapping map[data.MinecraftVersion]packetIDs) map[data.MinecraftVersion]packetIDs {
+	reverse := make(map[data.MinecraftVersion]packetIDs)
+
+	for version, states := range mapping {
+		reverse[version] = make(packetIDs)
+
+		for state, ids := range states {
+			reverse[version][state] = make(map[int32]int32)
+
+			for uuid, pid := range ids {
+				reverse[version][state][pid] = uuid
+			}
+		}
+	}
+
+	return reverse
+}
@@ -0,0 +1,47 @@
+package prot
+
+import (
+	"testing"
+
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/impl/base"
+)
+
+func TestRegistry_RoundTrip(t *testing.T) {
+	registry := NewRegistry()
+
+	for direction, states := range registry.create {
+		for state, packets := range states {
+			for uuid := range packets {
+				for _, version := range data.SupportedVersions {
+					pid, cont := registry.Map(direction, state, version, uuid)
+					if !cont {
+						continue // the version lacks the packet
+					}
+
+					packet := registry.Create(direction, state, version, pid)
+					if packet == nil {
+						t.Fatalf("%v %v %v: no packet for id 0x%02X", direction, state, version, pid)
+					}
+
+					if packet.UUID() != uuid {
+						t.Fatalf("%v %v %v: id 0x%02X created 0x%02X, expected 0x%02X", direction, state, version, pid, packet.UUID(), uuid)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRegistry_MissingPacket(t *testing.T) {
+	registry := NewRegistry()
+
+	// set difficulty was added in 1.14
+	if _, cont := registry.Map(base.SERVERBOUND, base.PLAY, data.MC1_13_2, 0x02); cont {
+		t.Fatal("set difficulty should not exist in 1.13.2")
+	}
+
+	if packet := registry.Create(base.SERVERBOUND, base.PLAY, data.CurrentProtocol, 0x7F); packet != nil {
+		t.Fatalf("expected no packet, got %T", packet)
+	}
+}
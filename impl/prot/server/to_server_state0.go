@@ -8,10 +8,10 @@ import (
 // done
 
 type PacketIHandshake struct {
-	version int32
+	Version int32
 
-	host string
-	port uint16
+	Host string // proxies using bungeecord forwarding append the player's address, uuid and properties
+	Port uint16
 
 	State base.PacketState
 }
@@ -20,13 +20,29 @@ func (p *PacketIHandshake) UUID() int32 {
 	return 0x00
 }
 
-func (p *PacketIHandshake) Pull(reader buff.Buffer, conn base.Connection) {
-	p.version = reader.PullVrI()
+func (p *PacketIHandshake) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushVrI(p.Version)
 
-	p.host = reader.PullTxt()
-	p.port = reader.PullU16()
+	writer.PushTxt(p.Host)
+	writer.PushI16(int16(p.Port))
+
+	writer.PushVrI(int32(p.State))
+}
+
+func (p *PacketIHandshake) Pull(reader buff.Buffer, conn base.Connection) error {
+	p.Version = reader.PullVrI()
+
+	p.Host = reader.PullTxt()
+	p.Port = reader.PullU16()
 
 	state := reader.PullVrI()
 
-	p.State = base.PacketStateValueOf(int(state))
+	if err := reader.Err(); err != nil {
+		return err
+	}
+
+	var err error
+	p.State, err = base.PacketStateValueOf(int(state))
+
+	return err
 }
@@ -14,10 +14,14 @@ func (p *PacketIRequest) UUID() int32 {
 	return 0x00
 }
 
-func (p *PacketIRequest) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIRequest) Push(writer buff.Buffer, conn base.Connection) {
 	// no fields
 }
 
+func (p *PacketIRequest) Pull(reader buff.Buffer, conn base.Connection) error {
+	return nil // no fields
+}
+
 type PacketIPing struct {
 	Ping int64
 }
@@ -26,6 +30,12 @@ func (p *PacketIPing) UUID() int32 {
 	return 0x01
 }
 
-func (p *PacketIPing) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIPing) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushI64(p.Ping)
+}
+
+func (p *PacketIPing) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.Ping = reader.PullI64()
+
+	return reader.Err()
 }
@@ -1,12 +1,18 @@
 package server
 
 import (
+	"fmt"
+	"unicode/utf8"
+
 	"github.com/golangmc/minecraft-server/apis/buff"
 	"github.com/golangmc/minecraft-server/impl/base"
 )
 
 // done
 
+// the longest name the vanilla server accepts
+const maxPlayerName = 16
+
 type PacketILoginStart struct {
 	PlayerName string
 }
@@ -15,8 +21,18 @@ func (p *PacketILoginStart) UUID() int32 {
 	return 0x00
 }
 
-func (p *PacketILoginStart) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketILoginStart) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushTxt(p.PlayerName)
+}
+
+func (p *PacketILoginStart) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.PlayerName = reader.PullTxt()
+
+	if length := utf8.RuneCountInString(p.PlayerName); length > maxPlayerName {
+		return fmt.Errorf("player name is %d characters long, at most %d are allowed", length, maxPlayerName)
+	}
+
+	return reader.Err()
 }
 
 type PacketIEncryptionResponse struct {
@@ -28,9 +44,16 @@ func (p *PacketIEncryptionResponse) UUID() int32 {
 	return 0x01
 }
 
-func (p *PacketIEncryptionResponse) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIEncryptionResponse) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushUAS(p.Secret, true)
+	writer.PushUAS(p.Verify, true)
+}
+
+func (p *PacketIEncryptionResponse) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.Secret = reader.PullUAS()
 	p.Verify = reader.PullUAS()
+
+	return reader.Err()
 }
 
 type PacketILoginPluginResponse struct {
@@ -43,8 +66,16 @@ func (p *PacketILoginPluginResponse) UUID() int32 {
 	return 0x02
 }
 
-func (p *PacketILoginPluginResponse) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketILoginPluginResponse) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushVrI(p.Message)
+	writer.PushBit(p.Success)
+	writer.PushUAS(p.OptData, false)
+}
+
+func (p *PacketILoginPluginResponse) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.Message = reader.PullVrI()
 	p.Success = reader.PullBit()
 	p.OptData = reader.UAS()[reader.InI():reader.Len()]
+
+	return reader.Err()
 }
@@ -2,6 +2,7 @@ package server
 
 import (
 	"github.com/golangmc/minecraft-server/apis/buff"
+	"github.com/golangmc/minecraft-server/apis/chns"
 	"github.com/golangmc/minecraft-server/apis/data"
 	"github.com/golangmc/minecraft-server/apis/game"
 	"github.com/golangmc/minecraft-server/impl/base"
@@ -17,8 +18,14 @@ func (p *PacketIKeepAlive) UUID() int32 {
 	return 0x0F
 }
 
-func (p *PacketIKeepAlive) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIKeepAlive) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushI64(p.KeepAliveID)
+}
+
+func (p *PacketIKeepAlive) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.KeepAliveID = reader.PullI64()
+
+	return reader.Err()
 }
 
 type PacketIChatMessage struct {
@@ -29,8 +36,14 @@ func (p *PacketIChatMessage) UUID() int32 {
 	return 0x03
 }
 
-func (p *PacketIChatMessage) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIChatMessage) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushTxt(p.Message)
+}
+
+func (p *PacketIChatMessage) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.Message = reader.PullTxt()
+
+	return reader.Err()
 }
 
 type PacketITeleportConfirm struct {
@@ -41,8 +54,14 @@ func (p *PacketITeleportConfirm) UUID() int32 {
 	return 0x00
 }
 
-func (p *PacketITeleportConfirm) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketITeleportConfirm) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushVrI(p.TeleportID)
+}
+
+func (p *PacketITeleportConfirm) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.TeleportID = reader.PullVrI()
+
+	return reader.Err()
 }
 
 type PacketIQueryBlockNBT struct {
@@ -54,9 +73,16 @@ func (p *PacketIQueryBlockNBT) UUID() int32 {
 	return 0x01
 }
 
-func (p *PacketIQueryBlockNBT) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIQueryBlockNBT) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushVrI(p.TransactionID)
+	writer.PushPos(p.Position)
+}
+
+func (p *PacketIQueryBlockNBT) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.TransactionID = reader.PullVrI()
 	p.Position = reader.PullPos()
+
+	return reader.Err()
 }
 
 type PacketISetDifficulty struct {
@@ -67,8 +93,21 @@ func (p *PacketISetDifficulty) UUID() int32 {
 	return 0x02
 }
 
-func (p *PacketISetDifficulty) Pull(reader buff.Buffer, conn base.Connection) {
-	p.Difficult = game.DifficultyValueOf(reader.PullByt())
+func (p *PacketISetDifficulty) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushByt(game.ValueOfDifficulty(p.Difficult))
+}
+
+func (p *PacketISetDifficulty) Pull(reader buff.Buffer, conn base.Connection) error {
+	difficulty := reader.PullByt()
+
+	if err := reader.Err(); err != nil {
+		return err
+	}
+
+	var err error
+	p.Difficult, err = game.DifficultyValueOf(difficulty)
+
+	return err
 }
 
 type PacketIPluginMessage struct {
@@ -79,17 +118,25 @@ func (p *PacketIPluginMessage) UUID() int32 {
 	return 0x0B
 }
 
-func (p *PacketIPluginMessage) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIPluginMessage) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushTxt(p.Message.Chan())
+	p.Message.Push(writer)
+}
+
+func (p *PacketIPluginMessage) Pull(reader buff.Buffer, conn base.Connection) error {
 	channel := reader.PullTxt()
+
 	message := plugin.GetMessageForChannel(channel)
 
 	if message == nil {
-		return // log unregistered channel?
+		message = &chns.Raw{Channel: channel} // unregistered channel
 	}
 
 	message.Pull(reader)
 
 	p.Message = message
+
+	return reader.Err()
 }
 
 type PacketIClientStatus struct {
@@ -100,8 +147,14 @@ func (p *PacketIClientStatus) UUID() int32 {
 	return 0x04
 }
 
-func (p *PacketIClientStatus) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIClientStatus) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushVrI(int32(p.Action))
+}
+
+func (p *PacketIClientStatus) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.Action = client.StatusAction(reader.PullVrI())
+
+	return reader.Err()
 }
 
 type PacketIClientSettings struct {
@@ -117,7 +170,18 @@ func (p *PacketIClientSettings) UUID() int32 {
 	return 0x05
 }
 
-func (p *PacketIClientSettings) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIClientSettings) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushTxt(p.Locale)
+	writer.PushByt(p.ViewDistance)
+	writer.PushVrI(int32(p.ChatMode))
+	writer.PushBit(p.ChatColors)
+
+	p.SkinParts.Push(writer)
+
+	writer.PushVrI(int32(p.MainHand))
+}
+
+func (p *PacketIClientSettings) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.Locale = reader.PullTxt()
 	p.ViewDistance = reader.PullByt()
 	p.ChatMode = client.ChatMode(reader.PullVrI())
@@ -128,6 +192,8 @@ func (p *PacketIClientSettings) Pull(reader buff.Buffer, conn base.Connection) {
 
 	p.SkinParts = parts
 	p.MainHand = client.MainHand(reader.PullVrI())
+
+	return reader.Err()
 }
 
 type PacketIPlayerAbilities struct {
@@ -140,7 +206,14 @@ func (p *PacketIPlayerAbilities) UUID() int32 {
 	return 0x19
 }
 
-func (p *PacketIPlayerAbilities) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIPlayerAbilities) Push(writer buff.Buffer, conn base.Connection) {
+	p.Abilities.Push(writer)
+
+	writer.PushF32(p.FlightSpeed)
+	writer.PushF32(p.GroundSpeed)
+}
+
+func (p *PacketIPlayerAbilities) Pull(reader buff.Buffer, conn base.Connection) error {
 	abilities := client.PlayerAbilities{}
 	abilities.Pull(reader)
 
@@ -148,6 +221,8 @@ func (p *PacketIPlayerAbilities) Pull(reader buff.Buffer, conn base.Connection)
 
 	p.FlightSpeed = reader.PullF32()
 	p.GroundSpeed = reader.PullF32()
+
+	return reader.Err()
 }
 
 type PacketIPlayerPosition struct {
@@ -159,7 +234,15 @@ func (p *PacketIPlayerPosition) UUID() int32 {
 	return 0x11
 }
 
-func (p *PacketIPlayerPosition) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIPlayerPosition) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushF64(p.Position.X)
+	writer.PushF64(p.Position.Y)
+	writer.PushF64(p.Position.Z)
+
+	writer.PushBit(p.OnGround)
+}
+
+func (p *PacketIPlayerPosition) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.Position = data.PositionF{
 		X: reader.PullF64(),
 		Y: reader.PullF64(),
@@ -167,6 +250,8 @@ func (p *PacketIPlayerPosition) Pull(reader buff.Buffer, conn base.Connection) {
 	}
 
 	p.OnGround = reader.PullBit()
+
+	return reader.Err()
 }
 
 type PacketIPlayerLocation struct {
@@ -178,7 +263,18 @@ func (p *PacketIPlayerLocation) UUID() int32 {
 	return 0x12
 }
 
-func (p *PacketIPlayerLocation) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIPlayerLocation) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushF64(p.Location.X)
+	writer.PushF64(p.Location.Y)
+	writer.PushF64(p.Location.Z)
+
+	writer.PushF32(p.Location.AxisX)
+	writer.PushF32(p.Location.AxisY)
+
+	writer.PushBit(p.OnGround)
+}
+
+func (p *PacketIPlayerLocation) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.Location = data.Location{
 		PositionF: data.PositionF{
 			X: reader.PullF64(),
@@ -192,6 +288,8 @@ func (p *PacketIPlayerLocation) Pull(reader buff.Buffer, conn base.Connection) {
 	}
 
 	p.OnGround = reader.PullBit()
+
+	return reader.Err()
 }
 
 type PacketIPlayerRotation struct {
@@ -203,11 +301,20 @@ func (p *PacketIPlayerRotation) UUID() int32 {
 	return 0x13
 }
 
-func (p *PacketIPlayerRotation) Pull(reader buff.Buffer, conn base.Connection) {
+func (p *PacketIPlayerRotation) Push(writer buff.Buffer, conn base.Connection) {
+	writer.PushF32(p.Rotation.AxisX)
+	writer.PushF32(p.Rotation.AxisY)
+
+	writer.PushBit(p.OnGround)
+}
+
+func (p *PacketIPlayerRotation) Pull(reader buff.Buffer, conn base.Connection) error {
 	p.Rotation = data.RotationF{
 		AxisX: reader.PullF32(),
 		AxisY: reader.PullF32(),
 	}
 
 	p.OnGround = reader.PullBit()
+
+	return reader.Err()
 }
@@ -0,0 +1,96 @@
+package rcon
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	typeResponse = 0
+	typeCommand  = 2
+	typeAuthed   = 2
+	typeLogin    = 3
+)
+
+const (
+	// the id and type before the body, and the two NULs after it
+	packetPadding = 10
+	// the longest body a client may send, the longest a response body is cut into
+	maxBodyLength = 4096
+)
+
+// the id an auth response carries when the password was wrong
+const failedAuth = -1
+
+type packet struct {
+	ID   int32
+	Type int32
+	Body string
+}
+
+// readPacket reads a single length prefixed packet, all integers are little endian
+func readPacket(reader io.Reader) (packet, error) {
+	var length int32
+
+	if err := binary.Read(reader, binary.LittleEndian, &length); err != nil {
+		return packet{}, err
+	}
+
+	if length < packetPadding || length > packetPadding+maxBodyLength {
+		return packet{}, fmt.Errorf("invalid packet length %d", length)
+	}
+
+	data := make([]byte, length)
+
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return packet{}, err
+	}
+
+	body := data[8 : length-2]
+
+	// clients are meant to terminate the body twice, the spec is loose enough that some only do it once
+	for len(body) > 0 && body[len(body)-1] == 0 {
+		body = body[:len(body)-1]
+	}
+
+	return packet{
+		ID:   int32(binary.LittleEndian.Uint32(data[0:4])),
+		Type: int32(binary.LittleEndian.Uint32(data[4:8])),
+		Body: string(body),
+	}, nil
+}
+
+func writePacket(writer io.Writer, packet packet) error {
+	data := make([]byte, 12, 4+packetPadding+len(packet.Body))
+
+	binary.LittleEndian.PutUint32(data[0:4], uint32(packetPadding+len(packet.Body)))
+	binary.LittleEndian.PutUint32(data[4:8], uint32(packet.ID))
+	binary.LittleEndian.PutUint32(data[8:12], uint32(packet.Type))
+
+	data = append(data, packet.Body...)
+	data = append(data, 0, 0)
+
+	_, err := writer.Write(data)
+	return err
+}
+
+// writeResponse sends the output in as many response packets as it takes, each carrying the id of the command
+func writeResponse(writer io.Writer, id int32, output string) error {
+	for {
+		body := output
+		if len(body) > maxBodyLength {
+			body = body[:maxBodyLength]
+		}
+
+		if err := writePacket(writer, packet{ID: id, Type: typeResponse, Body: body}); err != nil {
+			return err
+		}
+
+		output = output[len(body):]
+
+		if len(output) == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package rcon
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/golangmc/minecraft-server/apis/base"
+	"github.com/golangmc/minecraft-server/apis/cmds"
+	"github.com/golangmc/minecraft-server/apis/logs"
+	"github.com/golangmc/minecraft-server/impl/conf"
+)
+
+// Rcon runs commands sent over the source rcon protocol
+type Rcon struct {
+	config *conf.Network
+
+	logger  *logs.Logging
+	command *cmds.CommandManager
+
+	lock     sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]bool // true while the connection runs a command, it is closed once the response is written
+	closing  bool
+}
+
+func NewRcon(config *conf.Network, command *cmds.CommandManager) *Rcon {
+	return &Rcon{
+		config: config,
+
+		logger:  logs.NewLogging("rcon", logs.EveryLevel...),
+		command: command,
+
+		conns: make(map[net.Conn]bool),
+	}
+}
+
+// Load starts listening when rcon is enabled
+func (r *Rcon) Load() {
+	if !r.config.EnableRcon {
+		return
+	}
+
+	// vanilla refuses an empty password too, anyone could run commands
+	if r.config.RconPassword == "" {
+		r.logger.WarnF("rcon is enabled without a password, it will not be started")
+		return
+	}
+
+	listener, err := net.Listen("tcp", r.config.RconAddress)
+	if err != nil {
+		r.logger.FailF("rcon unavailable: failed to bind [%v]", err)
+		return
+	}
+
+	r.lock.Lock()
+	r.listener = listener
+	r.lock.Unlock()
+
+	r.logger.InfoF("listening on %s", r.config.RconAddress)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				break // closed
+			}
+
+			if !r.track(conn) {
+				_ = conn.Close()
+				continue
+			}
+
+			go func() {
+				defer r.untrack(conn)
+
+				if err := r.handle(conn); err != nil && err != io.EOF {
+					r.logger.WarnF("closing rcon connection from %v: %v", conn.RemoteAddr(), err)
+				}
+			}()
+		}
+	}()
+}
+
+// Kill stops listening and closes every connection
+func (r *Rcon) Kill() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.closing = true
+
+	if r.listener != nil {
+		_ = r.listener.Close()
+	}
+
+	for conn, running := range r.conns {
+		if !running {
+			_ = conn.Close()
+		}
+	}
+}
+
+func (r *Rcon) track(conn net.Conn) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closing {
+		return false
+	}
+
+	r.conns[conn] = false
+
+	return true
+}
+
+// running marks the connection while a command runs on it, false once the command finished and rcon is closing
+func (r *Rcon) running(conn net.Conn, running bool) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.conns[conn]; ok {
+		r.conns[conn] = running
+	}
+
+	return running || !r.closing
+}
+
+func (r *Rcon) untrack(conn net.Conn) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_ = conn.Close()
+	delete(r.conns, conn)
+}
+
+func (r *Rcon) handle(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	authed := false
+
+	for {
+		request, err := readPacket(reader)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case request.Type == typeLogin:
+			authed = subtle.ConstantTimeCompare([]byte(request.Body), []byte(r.config.RconPassword)) == 1
+
+			id := request.ID
+			if !authed {
+				id = failedAuth
+				r.logger.WarnF("rcon connection from %v used a wrong password", conn.RemoteAddr())
+			}
+
+			if err := writePacket(conn, packet{ID: id, Type: typeAuthed}); err != nil {
+				return err
+			}
+		case !authed:
+			return fmt.Errorf("sent a request before logging in")
+		case request.Type == typeCommand:
+			r.logger.InfoF("%v issued server command: %s", conn.RemoteAddr(), request.Body)
+
+			// a command stopping the server closes rcon, its response is still written
+			r.running(conn, true)
+
+			if err := writeResponse(conn, request.ID, r.Evaluate(request.Body)); err != nil {
+				return err
+			}
+
+			if !r.running(conn, false) {
+				return io.EOF
+			}
+		default:
+			// clients follow a command with an empty response to find the end of a fragmented one, it is mirrored back
+			if err := writePacket(conn, packet{ID: request.ID, Type: typeResponse}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Evaluate runs the command line as a new Sender and returns everything sent to it
+func (r *Rcon) Evaluate(line string) string {
+	args := strings.Split(strings.TrimPrefix(strings.Trim(line, " "), "/"), " ")
+
+	sender := &Sender{}
+
+	command := r.command.Search(args[0])
+	if command == nil {
+		return fmt.Sprintf("Command with name \"%s\" is undefined", args[0])
+	}
+
+	err := base.Attempt(func() {
+		(*command).Evaluate(sender, args[1:])
+	})
+
+	if err != nil {
+		r.logger.FailF("failed to evaluate `%s`: %v", (*command).Name(), err)
+		sender.SendMessage("An error occurred while executing the command")
+	}
+
+	return sender.Output()
+}
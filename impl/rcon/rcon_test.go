@@ -0,0 +1,108 @@
+package rcon
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/golangmc/minecraft-server/apis/cmds"
+	"github.com/golangmc/minecraft-server/apis/ents"
+	"github.com/golangmc/minecraft-server/impl/conf"
+)
+
+func testSession() net.Conn {
+	command := cmds.NewCommandManager()
+	command.Register("echo", func(sender ents.Sender, params []string) {
+		for _, param := range params {
+			sender.SendMessage(param)
+		}
+	})
+	command.Register("long", func(sender ents.Sender, params []string) {
+		sender.SendMessage(strings.Repeat("a", maxBodyLength+10))
+	})
+
+	rcon := NewRcon(&conf.Network{RconPassword: "secret"}, command)
+
+	server, client := net.Pipe()
+
+	go func() {
+		_ = rcon.handle(server)
+		_ = server.Close()
+	}()
+
+	return client
+}
+
+func exchange(t *testing.T, conn net.Conn, request packet) packet {
+	go func() {
+		_ = writePacket(conn, request)
+	}()
+
+	response, err := readPacket(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response
+}
+
+func TestRcon_Auth(t *testing.T) {
+	conn := testSession()
+	defer conn.Close()
+
+	if response := exchange(t, conn, packet{ID: 7, Type: typeLogin, Body: "wrong"}); response.ID != failedAuth || response.Type != typeAuthed {
+		t.Fatalf("a wrong password was answered with %+v", response)
+	}
+
+	if response := exchange(t, conn, packet{ID: 7, Type: typeLogin, Body: "secret"}); response.ID != 7 || response.Type != typeAuthed {
+		t.Fatalf("the password was answered with %+v", response)
+	}
+
+	if response := exchange(t, conn, packet{ID: 8, Type: typeCommand, Body: "echo hello world"}); response.ID != 8 || response.Body != "hello\nworld" {
+		t.Fatalf("the command was answered with %+v", response)
+	}
+}
+
+func TestRcon_Unauthed(t *testing.T) {
+	conn := testSession()
+	defer conn.Close()
+
+	go func() {
+		_ = writePacket(conn, packet{ID: 1, Type: typeCommand, Body: "echo hello"})
+	}()
+
+	if response, err := readPacket(conn); err == nil {
+		t.Fatalf("a command was run without logging in: %+v", response)
+	}
+}
+
+func TestRcon_Fragmented(t *testing.T) {
+	conn := testSession()
+	defer conn.Close()
+
+	exchange(t, conn, packet{ID: 1, Type: typeLogin, Body: "secret"})
+
+	go func() {
+		_ = writePacket(conn, packet{ID: 2, Type: typeCommand, Body: "long"})
+		_ = writePacket(conn, packet{ID: 3, Type: typeResponse})
+	}()
+
+	output := ""
+
+	for {
+		response, err := readPacket(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.ID == 3 {
+			break // the end of the output
+		}
+
+		output += response.Body
+	}
+
+	if output != strings.Repeat("a", maxBodyLength+10) {
+		t.Fatalf("reassembled %d bytes of output", len(output))
+	}
+}
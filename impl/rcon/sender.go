@@ -0,0 +1,38 @@
+package rcon
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/golangmc/minecraft-server/apis/base"
+	"github.com/golangmc/minecraft-server/apis/uuid"
+)
+
+// Sender runs a single command for a remote console, collecting every message sent to it
+type Sender struct {
+	lock   sync.Mutex
+	output []string
+}
+
+func (s *Sender) Name() string {
+	return "Rcon"
+}
+
+func (s *Sender) UUID() uuid.UUID {
+	return uuid.TextToUUID(s.Name())
+}
+
+func (s *Sender) SendMessage(message ...interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.output = append(s.output, base.ConvertToString(message...))
+}
+
+// Output is every message sent so far, one per line
+func (s *Sender) Output() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return strings.Join(s.output, "\n")
+}
@@ -2,6 +2,8 @@ package impl
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"github.com/golangmc/minecraft-server/apis/data"
 	apis_level "github.com/golangmc/minecraft-server/apis/game/level"
 	impl_level "github.com/golangmc/minecraft-server/impl/game/level"
@@ -9,9 +11,11 @@ import (
 	"github.com/golangmc/minecraft-server/lib"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golangmc/minecraft-server/apis"
+	"github.com/golangmc/minecraft-server/apis/chns"
 	"github.com/golangmc/minecraft-server/apis/cmds"
 	"github.com/golangmc/minecraft-server/apis/data/chat"
 	"github.com/golangmc/minecraft-server/apis/ents"
@@ -21,12 +25,14 @@ import (
 	"github.com/golangmc/minecraft-server/apis/uuid"
 	"github.com/golangmc/minecraft-server/impl/conf"
 	"github.com/golangmc/minecraft-server/impl/data/plugin"
+	"github.com/golangmc/minecraft-server/impl/data/status"
 
 	"github.com/golangmc/minecraft-server/impl/conn"
 	"github.com/golangmc/minecraft-server/impl/cons"
 	"github.com/golangmc/minecraft-server/impl/data/system"
 	"github.com/golangmc/minecraft-server/impl/data/values"
 	"github.com/golangmc/minecraft-server/impl/prot"
+	"github.com/golangmc/minecraft-server/impl/rcon"
 
 	apis_base "github.com/golangmc/minecraft-server/apis/base"
 	impl_base "github.com/golangmc/minecraft-server/impl/base"
@@ -41,6 +47,7 @@ type Properties struct {
 
 type server struct {
 	message chan system.Message
+	killed  sync.Once
 
 	console *cons.Console
 
@@ -48,11 +55,14 @@ type server struct {
 	tasking *task.Tasking
 	watcher util.Watcher
 
-	command *cmds.CommandManager
+	command  *cmds.CommandManager
+	channels *plugin.Channels
 
 	network impl_base.Network
 	packets impl_base.Packets
 
+	rcon *rcon.Rcon
+
 	players *playerAssociation
 
 	config *conf.ServerConfig
@@ -61,7 +71,7 @@ type server struct {
 }
 
 // NewServer ==== new ====
-func NewServer(conf *conf.ServerConfig) apis.Server {
+func NewServer(conf *conf.ServerConfig) (apis.Server, error) {
 	message := make(chan system.Message)
 
 	console := cons.NewConsole(message)
@@ -73,12 +83,9 @@ func NewServer(conf *conf.ServerConfig) apis.Server {
 	join := make(chan impl_base.PlayerAndConnection)
 	quit := make(chan impl_base.PlayerAndConnection)
 
-	packets := prot.NewPackets(conf, tasking, join, quit)
-	network := conn.NewNetwork(conf.Network.Host, conf.Network.Port, packets, message, join, quit)
-
 	command := cmds.NewCommandManager()
 
-	return &server{
+	s := &server{
 		message: message,
 
 		console: console,
@@ -89,18 +96,49 @@ func NewServer(conf *conf.ServerConfig) apis.Server {
 
 		command: command,
 
-		packets: packets,
-		network: network,
+		rcon: rcon.NewRcon(&conf.Network, command),
 
 		config: conf,
 
 		players: &playerAssociation{
 			uuidToData: make(map[uuid.UUID]ents.Player),
 
-			connToUUID: make(map[impl_base.Connection]uuid.UUID),
+			connToData: make(map[impl_base.Connection]ents.Player),
 			uuidToConn: make(map[uuid.UUID]impl_base.Connection),
 		},
 	}
+
+	s.channels = plugin.NewChannels(s.sendPluginMessage)
+
+	// the handlers reach the players through this server, not the global one, so tests can run several
+	packets, err := prot.NewPackets(s, conf, tasking, join, quit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.packets = packets
+	s.network = conn.NewNetwork(s, conf, s.packets, message, join, quit)
+
+	return s, nil
+}
+
+// Replay feeds a packet capture into the handlers of a server that never starts listening, see conn.Replay
+func Replay(conf *conf.ServerConfig, reader io.Reader, sent func(conn impl_base.Connection, packet impl_base.PacketO)) error {
+	created, err := NewServer(conf)
+	if err != nil {
+		return err
+	}
+
+	s := created.(*server)
+
+	apis.SetMinecraftServer(s)
+
+	s.loadWorld()
+	s.tasking.Load()
+
+	defer s.tasking.Kill()
+
+	return conn.Replay(reader, s.packets, sent)
 }
 
 // Load ==== State ====
@@ -114,19 +152,23 @@ func (s *server) Load() {
 	s.wait()
 }
 
+// Kill stops the server once, the console and rcon may both ask for it
 func (s *server) Kill() {
-	lib.ReadLine().Close()
+	s.killed.Do(func() {
+		lib.ReadLine().Close()
 
-	s.console.Kill()
-	s.command.Kill()
-	s.tasking.Kill()
-	s.network.Kill()
+		s.console.Kill()
+		s.command.Kill()
+		s.tasking.Kill()
+		s.network.Kill()
+		s.rcon.Kill()
 
-	// push the stop message to the server exit channel
-	s.message <- system.Make(system.STOP, "normal stop")
-	close(s.message)
+		// push the stop message to the server exit channel
+		s.message <- system.Make(system.STOP, "normal stop")
+		close(s.message)
 
-	s.logging.Info(chat.DarkRed, "Server will be stopped")
+		s.logging.Info(chat.DarkRed, "Server will be stopped")
+	})
 }
 
 // Logging ==== Server ====
@@ -138,6 +180,10 @@ func (s *server) Command() *cmds.CommandManager {
 	return s.command
 }
 
+func (s *server) Channels() chns.Channels {
+	return s.channels
+}
+
 func (s *server) Tasking() *task.Tasking {
 	return s.tasking
 }
@@ -147,30 +193,19 @@ func (s *server) Watcher() util.Watcher {
 }
 
 func (s *server) Players() []ents.Player {
-	players := make([]ents.Player, 0)
-
-	for _, player := range s.players.uuidToData {
-		players = append(players, player)
-	}
-
-	return players
+	return s.players.allPlayers()
 }
 
 func (s *server) ConnByUUID(uuid uuid.UUID) impl_base.Connection {
-	return s.players.uuidToConn[uuid]
+	return s.players.connByUUID(uuid)
 }
 
 func (s *server) PlayerByUUID(uuid uuid.UUID) ents.Player {
-	return s.players.uuidToData[uuid]
+	return s.players.playerByUUID(uuid)
 }
 
 func (s *server) PlayerByConn(conn impl_base.Connection) ents.Player {
-	uuid, con := s.players.connToUUID[conn]
-	if !con {
-		return nil
-	}
-
-	return s.PlayerByUUID(uuid)
+	return s.players.playerByConn(conn)
 }
 
 func (s *server) ServerVersion() string {
@@ -195,7 +230,9 @@ func (s *server) broadcastCommand(sender ents.Sender, params []string) {
 }
 
 func (s *server) stopServerCommand(sender ents.Sender, params []string) {
-	if _, ok := sender.(*cons.Console); !ok {
+	switch sender.(type) {
+	case *cons.Console, *rcon.Sender:
+	default:
 		s.logging.FailF("non console sender %s tried to stop the server", sender.Name())
 		return
 	}
@@ -218,7 +255,10 @@ func (s *server) stopServerCommand(sender ents.Sender, params []string) {
 
 	if after == 0 {
 
-		s.Kill()
+		sender.SendMessage("Stopping the server")
+
+		// the sender gets its answer first, rcon would lose it to the connection closing
+		go s.Kill()
 
 	} else {
 
@@ -234,7 +274,7 @@ func (s *server) stopServerCommand(sender ents.Sender, params []string) {
 }
 
 func (s *server) setBlockCommand(sender ents.Sender, params []string) {
-	if _, ok := sender.(*cons.Console); ok {
+	if _, ok := sender.(ents.Player); !ok {
 		sender.SendMessage("Sorry but you can't execute this command!")
 		return
 	}
@@ -253,14 +293,14 @@ func (s *server) setBlockCommand(sender ents.Sender, params []string) {
 
 	sender.SendMessage("Trying to set block around you.")
 
-	conn := s.players.uuidToConn[sender.UUID()]
+	conn := s.players.connByUUID(sender.UUID())
 	for _, chunk := range s.GetLevel().Chunks() {
 		conn.SendPacket(&client_packet.PacketOChunkData{Chunk: chunk})
 	}
 }
 
 func (s *server) teleportCommand(sender ents.Sender, params []string) {
-	if _, ok := sender.(*cons.Console); ok {
+	if _, ok := sender.(ents.Player); !ok {
 		sender.SendMessage(chat.Translate("&cOnly user can run this command."))
 		return
 	}
@@ -296,26 +336,37 @@ func (s *server) versionCommand(sender ents.Sender, params []string) {
 func (s *server) loadServer() {
 	s.console.Load()
 	s.command.Load()
-	s.tasking.Load()
-	s.network.Load()
 
-	s.logRunningStatus()
+	s.loadNetwork()
+	s.rcon.Load()
 
 	s.command.Register("vers", s.versionCommand)
 	s.command.Register("send", s.broadcastCommand)
 	s.command.Register("stop", s.stopServerCommand)
 	s.command.Register("tp", s.teleportCommand)
 	s.command.Register("setblock", s.setBlockCommand)
+}
+
+// loadNetwork starts everything players need to join, without the console, commands or rcon
+func (s *server) loadNetwork() {
+	s.tasking.Load()
 
 	s.watcher.SubAs(func(event apis_event.PlayerJoinEvent) {
 		s.logging.InfoF("player %s logged in with uuid:%v", event.Player.Name(), event.Player.UUID())
 
 		s.Broadcast(chat.Translate(fmt.Sprintf("%s%s has joined!", chat.Yellow, event.Player.Name())))
+
+		// tell the client which channels plugins listen on
+		if channels := s.channels.Registered(); len(channels) > 0 {
+			s.channels.Send(event.Player, &plugin.Register{Channels: channels})
+		}
 	})
 	s.watcher.SubAs(func(event apis_event.PlayerQuitEvent) {
 		s.logging.InfoF("%s disconnected!", event.Player.Name())
 
 		s.Broadcast(chat.Translate(fmt.Sprintf("%s%s has left!", chat.Yellow, event.Player.Name())))
+
+		s.channels.Forget(event.Player)
 	})
 
 	s.watcher.SubAs(func(event impl_event.PlayerConnJoinEvent) {
@@ -340,7 +391,30 @@ func (s *server) loadServer() {
 		case plugin.CHANNEL_BRAND:
 			s.logging.DataF("their client's brand is '%s'", event.Message.(*plugin.Brand).Name)
 		}
+
+		s.channels.Receive(event.Conn.Player, event.Message)
 	})
+
+	// a missing icon is fine, the server list shows none
+	favicon, err := status.Favicon(s.config.ServerIcon)
+	if err != nil && !os.IsNotExist(err) {
+		s.logging.WarnF("failed to load the server icon: %v", err)
+	}
+
+	s.config.Favicon = favicon
+
+	s.network.Load()
+
+	s.logRunningStatus()
+}
+
+func (s *server) sendPluginMessage(player ents.Player, message plugin.Message) {
+	conn := s.ConnByUUID(player.UUID())
+	if conn == nil {
+		return // left already
+	}
+
+	conn.SendPacket(&client_packet.PacketOPluginMessage{Message: message})
 }
 
 func (s *server) logRunningStatus() {
@@ -419,48 +493,73 @@ func (s *server) GetLevel() apis_level.Level {
 
 // ==== players ====
 type playerAssociation struct {
+	lock sync.RWMutex
+
 	uuidToData map[uuid.UUID]ents.Player
 
-	connToUUID map[impl_base.Connection]uuid.UUID
+	// a connection keeps its player after a duplicate login took over the uuid, until it quits
+	connToData map[impl_base.Connection]ents.Player
 	uuidToConn map[uuid.UUID]impl_base.Connection
 }
 
 func (p *playerAssociation) addData(data impl_base.PlayerAndConnection) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
 	p.uuidToData[data.Player.UUID()] = data.Player
 
-	p.connToUUID[data.Connection] = data.Player.UUID()
+	p.connToData[data.Connection] = data.Player
 	p.uuidToConn[data.Player.UUID()] = data.Connection
 }
 
 func (p *playerAssociation) delData(data impl_base.PlayerAndConnection) {
-	player := p.playerByConn(data.Connection)
+	p.lock.Lock()
+	defer p.lock.Unlock()
 
-	uuid := p.connToUUID[data.Connection]
+	player, con := p.connToData[data.Connection]
+	if !con {
+		return
+	}
 
-	delete(p.connToUUID, data.Connection)
-	delete(p.uuidToConn, uuid)
+	delete(p.connToData, data.Connection)
 
-	if player != nil {
+	// the uuid may belong to a newer connection already
+	if p.uuidToConn[player.UUID()] == data.Connection {
+		delete(p.uuidToConn, player.UUID())
 		delete(p.uuidToData, player.UUID())
 	}
 }
 
-func (p *playerAssociation) playerByUUID(uuid uuid.UUID) ents.Player {
-	return p.uuidToData[uuid]
-}
+func (p *playerAssociation) allPlayers() []ents.Player {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
 
-func (p *playerAssociation) playerByConn(conn impl_base.Connection) ents.Player {
-	uuid, con := p.connToUUID[conn]
+	players := make([]ents.Player, 0, len(p.uuidToData))
 
-	if !con {
-		return nil
+	for _, player := range p.uuidToData {
+		players = append(players, player)
 	}
 
-	data, con := p.uuidToData[uuid]
+	return players
+}
 
-	if !con {
-		return nil
-	}
+func (p *playerAssociation) connByUUID(uuid uuid.UUID) impl_base.Connection {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.uuidToConn[uuid]
+}
+
+func (p *playerAssociation) playerByUUID(uuid uuid.UUID) ents.Player {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
 
-	return data
-}
\ No newline at end of file
+	return p.uuidToData[uuid]
+}
+
+func (p *playerAssociation) playerByConn(conn impl_base.Connection) ents.Player {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.connToData[conn]
+}
@@ -0,0 +1,690 @@
+package impl
+
+import (
+	"encoding/binary"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golangmc/minecraft-server/apis/chns"
+	"github.com/golangmc/minecraft-server/apis/data"
+	"github.com/golangmc/minecraft-server/apis/ents"
+	apis_event "github.com/golangmc/minecraft-server/apis/game/event"
+	"github.com/golangmc/minecraft-server/apis/uuid"
+	"github.com/golangmc/minecraft-server/impl/base"
+	"github.com/golangmc/minecraft-server/impl/bots"
+	"github.com/golangmc/minecraft-server/impl/conf"
+	"github.com/golangmc/minecraft-server/impl/conn"
+	"github.com/golangmc/minecraft-server/impl/data/plugin"
+	"github.com/golangmc/minecraft-server/impl/prot/client"
+	server_packet "github.com/golangmc/minecraft-server/impl/prot/server"
+)
+
+// how long the tests wait on the server, sending the world is slow under the race detector
+const awaitTimeout = 30 * time.Second
+
+// startServer runs a server of its own for the test on a free loopback port, configure changes its config before it starts
+func startServer(t *testing.T, configure func(config *conf.ServerConfig)) (*server, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	config := conf.DefaultServerConfig
+	config.Network.Host = "127.0.0.1"
+	config.Network.Port = port
+	config.Network.ConnectionThrottle = 0
+	config.Network.MaxConnectionsPerIP = 0
+	config.Network.KeepAliveInterval = 1
+	config.Network.KeepAliveTimeout = 3
+
+	if configure != nil {
+		configure(&config)
+	}
+
+	created, err := NewServer(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := created.(*server)
+
+	// the console is not loaded, what is sent to it is dropped
+	go func() {
+		for range s.console.OChannel {
+		}
+	}()
+
+	// the console writes its log next to the test
+	_ = os.Remove("latest.log")
+
+	s.loadWorld()
+	s.loadNetwork()
+
+	t.Cleanup(func() {
+		s.network.Kill()
+		s.tasking.Kill()
+	})
+
+	return s, net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+}
+
+func TestServer_Status(t *testing.T) {
+	_, address := startServer(t, nil)
+
+	for _, version := range data.SupportedVersions {
+		response, _, err := bots.NewBot("status", version).Status(address)
+		if err != nil {
+			t.Fatalf("%v: %v", version, err)
+		}
+
+		if response.Version.Protocol != version.Protocol() {
+			t.Fatalf("%v: the status reports protocol %d", version, response.Version.Protocol)
+		}
+	}
+}
+
+func TestServer_StatusPing(t *testing.T) {
+	s, address := startServer(t, nil)
+
+	listed := bots.NewBot("listed", data.MC1_15_2)
+	if err := listed.Join(address); err != nil {
+		t.Fatal(err)
+	}
+
+	defer listed.Close()
+
+	handler := s.Watcher().SubAs(func(ping *apis_event.ServerListPingEvent) {
+		ping.Motd = "pinged by " + strconv.Itoa(ping.Protocol)
+		ping.SetCancelled(ping.Protocol == data.MC1_13_2.Protocol())
+	})
+
+	t.Cleanup(handler.UnSub)
+
+	response, _, err := bots.NewBot("status", data.MC1_15_2).Status(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Description.Text != "pinged by 578" {
+		t.Fatalf("the event did not change the motd, %q", response.Description.Text)
+	}
+
+	if response.Players.Online == 0 || response.Players.Max != conf.DefaultServerConfig.MaxPlayers {
+		t.Fatalf("unexpected players %+v", response.Players)
+	}
+
+	found := false
+	for _, sample := range response.Players.Sample {
+		found = found || sample.Name == "listed"
+	}
+
+	if !found {
+		t.Fatalf("the sample %+v misses the joined bot", response.Players.Sample)
+	}
+
+	if _, _, err := bots.NewBot("status", data.MC1_13_2).Status(address); err == nil {
+		t.Fatal("a cancelled ping was answered")
+	}
+}
+
+func TestServer_Favicon(t *testing.T) {
+	dir, err := ioutil.TempDir("", "favicon")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	icon := filepath.Join(dir, "server-icon.png")
+
+	_, before := startServer(t, func(config *conf.ServerConfig) {
+		config.ServerIcon = icon
+	})
+
+	// the icon is read when each server loads, the one above has none
+	file, err := os.Create(icon)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = png.Encode(file, image.NewRGBA(image.Rect(0, 0, 64, 64)))
+	_ = file.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, after := startServer(t, func(config *conf.ServerConfig) {
+		config.ServerIcon = icon
+	})
+
+	if response, _, err := bots.NewBot("status", data.CurrentProtocol).Status(before); err != nil || response.Favicon != "" {
+		t.Fatalf("the icon written after the server loaded is shown, %v", err)
+	}
+
+	if response, _, err := bots.NewBot("status", data.CurrentProtocol).Status(after); err != nil || !strings.HasPrefix(response.Favicon, "data:image/png;base64,") {
+		t.Fatalf("the icon is not shown, %v", err)
+	}
+}
+
+func TestServer_Join(t *testing.T) {
+	_, address := startServer(t, nil)
+
+	for _, version := range data.SupportedVersions {
+		bot := bots.NewBot("bot"+strconv.Itoa(version.Protocol()), version)
+
+		if err := bot.Join(address); err != nil {
+			t.Fatalf("%v: %v", version, err)
+		}
+
+		if !awaitPacket(bot, &client.PacketOJoinGame{}) {
+			t.Fatalf("%v: no join game, %v", version, bot.Err())
+		}
+
+		_ = bot.Close()
+	}
+
+	// 1.12.2 is not supported, its client is told to update
+	if err := bots.NewBot("legacy", data.MC1_12_2).Join(address); err == nil || !strings.Contains(err.Error(), "outdated_client") {
+		t.Fatalf("1.12.2 was not refused, %v", err)
+	}
+}
+
+func TestServer_OnlineMode(t *testing.T) {
+	session, err := bots.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer session.Close()
+
+	s, address := startServer(t, func(config *conf.ServerConfig) {
+		config.OnlineMode = true
+		config.SessionServer = session.URL()
+		config.PreventProxyConnections = true
+	})
+
+	for _, version := range []data.MinecraftVersion{data.MC1_13_2, data.MC1_15_2} {
+		name := "online" + strconv.Itoa(version.Protocol())
+
+		bot := bots.NewBot(name, version)
+		bot.Authenticate = session.Authenticate(bot)
+
+		if err := bot.Join(address); err != nil {
+			t.Fatalf("%v: %v", version, err)
+		}
+
+		// everything after the encryption response is encrypted
+		if !awaitPacket(bot, &client.PacketOJoinGame{}) {
+			t.Fatalf("%v: no join game, %v", version, bot.Err())
+		}
+
+		if conn := s.ConnByUUID(session.Profile(name)); conn == nil {
+			t.Fatalf("%v: the player did not get the session profile", version)
+		}
+
+		_ = bot.Close()
+	}
+
+	unjoined := bots.NewBot("unjoined", data.MC1_15_2)
+
+	if err := unjoined.Join(address); err == nil || !strings.Contains(err.Error(), "Authentication failed") {
+		t.Fatalf("joined without a session, %v", err)
+	}
+}
+
+func TestServer_DuplicateLogin(t *testing.T) {
+	s, address := startServer(t, nil)
+
+	twin := uuid.TextToUUID("OfflinePlayer:twin")
+
+	first := bots.NewBot("twin", data.MC1_15_2)
+	if err := first.Join(address); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server adds players after login success
+	if !awaitConn(s, twin, func(conn base.Connection) bool { return conn != nil }) {
+		t.Fatal("the first session was not added")
+	}
+
+	kicked := s.ConnByUUID(twin)
+
+	second := bots.NewBot("twin", data.MC1_13_2)
+	if err := second.Join(address); err != nil {
+		t.Fatal(err)
+	}
+
+	defer second.Close()
+
+	if !awaitConn(s, twin, func(conn base.Connection) bool { return conn != nil && conn != kicked }) {
+		t.Fatal("the second session was not added")
+	}
+
+	select {
+	case <-first.Done():
+		if err := first.Err(); err == nil || !strings.Contains(err.Error(), "another location") {
+			t.Fatalf("the first session ended with %v", err)
+		}
+	case <-time.After(awaitTimeout):
+		t.Fatal("the first session was not kicked")
+	}
+
+	twins := 0
+	for _, player := range s.Players() {
+		if player.Name() == "twin" {
+			twins++
+		}
+	}
+
+	if twins != 1 {
+		t.Fatalf("%d players named twin are online", twins)
+	}
+
+}
+
+func TestServer_RejectDuplicateLogin(t *testing.T) {
+	s, address := startServer(t, func(config *conf.ServerConfig) {
+		config.RejectDuplicateLogins = true
+	})
+
+	twin := uuid.TextToUUID("OfflinePlayer:twin")
+
+	first := bots.NewBot("twin", data.MC1_15_2)
+	if err := first.Join(address); err != nil {
+		t.Fatal(err)
+	}
+
+	defer first.Close()
+
+	if !awaitConn(s, twin, func(conn base.Connection) bool { return conn != nil }) {
+		t.Fatal("the first session was not added")
+	}
+
+	second := bots.NewBot("twin", data.MC1_15_2)
+	if err := second.Join(address); err == nil || !strings.Contains(err.Error(), "already logged in") {
+		t.Fatalf("the second session joined, %v", err)
+	}
+
+	select {
+	case <-first.Done():
+		t.Fatalf("the first session was kicked, %v", first.Err())
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestServer_KeepAlive(t *testing.T) {
+	_, address := startServer(t, nil)
+
+	bot := bots.NewBot("keepalive", data.SupportedVersions[len(data.SupportedVersions)-1])
+	if err := bot.Join(address); err != nil {
+		t.Fatal(err)
+	}
+
+	defer bot.Close()
+
+	if !awaitPacket(bot, &client.PacketOKeepAlive{}) {
+		t.Fatalf("no keep alive, %v", bot.Err())
+	}
+
+	// the bot answers, so it outlives the timeout
+	bot.Move(data.PositionF{X: 1, Y: 64, Z: 1}, true)
+
+	select {
+	case <-bot.Done():
+		t.Fatalf("disconnected: %v", bot.Err())
+	case <-time.After(4 * time.Second):
+	}
+}
+
+func TestServer_Timeouts(t *testing.T) {
+	_, address := startServer(t, func(config *conf.ServerConfig) {
+		config.Network.HandshakeTimeout = 1
+		config.Network.LoginTimeout = 1
+	})
+
+	tests := []struct {
+		name   string
+		state  base.PacketState
+		shake  bool
+		reason string
+	}{
+		{name: "handshake", shake: false},
+		{name: "status", state: base.STATUS, shake: true},
+		{name: "login", state: base.LOGIN, shake: true, reason: "multiplayer.disconnect.slow_login"},
+		{name: "play", state: base.PLAY, shake: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tcp, err := net.Dial("tcp", address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer tcp.Close()
+
+			if test.shake {
+				if _, err := tcp.Write(handshake(address, test.state)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_ = tcp.SetReadDeadline(time.Now().Add(awaitTimeout))
+
+			// the server sends the disconnect, if the state has one, then closes the connection
+			received, err := ioutil.ReadAll(tcp)
+			if err != nil {
+				t.Fatalf("the connection was not closed: %v", err)
+			}
+
+			if !strings.Contains(string(received), test.reason) {
+				t.Fatalf("expected the reason %q, received %q", test.reason, received)
+			}
+		})
+	}
+}
+
+func TestServer_UnexpectedEncryption(t *testing.T) {
+	_, address := startServer(t, func(config *conf.ServerConfig) {
+		config.OnlineMode = true
+	})
+
+	tcp, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tcp.Close()
+
+	// an encryption response the server never asked for
+	packet := conn.NewBuffer()
+	packet.PushVrI(0x01)
+	packet.PushUAS(nil, true)
+	packet.PushUAS(nil, true)
+
+	frame := conn.NewBuffer()
+	frame.PushVrI(int32(packet.Len()))
+	frame.PushUAS(packet.UAS(), false)
+
+	if _, err := tcp.Write(append(handshake(address, base.LOGIN), frame.UAS()...)); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = tcp.SetReadDeadline(time.Now().Add(awaitTimeout))
+
+	received, err := ioutil.ReadAll(tcp)
+	if err != nil {
+		t.Fatalf("the connection was not closed: %v", err)
+	}
+
+	if !strings.Contains(string(received), "Unexpected login packet") {
+		t.Fatalf("expected the connection to be refused, received %q", received)
+	}
+}
+
+func TestServer_LongHandshake(t *testing.T) {
+	_, address := startServer(t, nil)
+
+	tcp, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tcp.Close()
+
+	// the length prefix of a 254 byte handshake starts like a legacy ping, FE 01
+	shake := handshakeHost(address, strings.Repeat("a", 246), base.STATUS)
+	if shake[0] != 0xFE || shake[1] != 0x01 {
+		t.Fatalf("the handshake starts with %x", shake[:2])
+	}
+
+	if _, err := tcp.Write(append(shake, 0x01, 0x00)); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = tcp.SetReadDeadline(time.Now().Add(awaitTimeout))
+
+	received := make([]byte, 0)
+	buffer := make([]byte, 4096)
+
+	for !strings.Contains(string(received), `"protocol"`) {
+		read, err := tcp.Read(buffer)
+		if err != nil {
+			t.Fatalf("no status response, received %q: %v", received, err)
+		}
+
+		received = append(received, buffer[:read]...)
+	}
+}
+
+func TestServer_RconStop(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rconAddress := listener.Addr().String()
+	_ = listener.Close()
+
+	s, _ := startServer(t, func(config *conf.ServerConfig) {
+		config.Network.EnableRcon = true
+		config.Network.RconAddress = rconAddress
+		config.Network.RconPassword = "secret"
+	})
+
+	s.command.Register("stop", s.stopServerCommand)
+	s.rcon.Load()
+
+	stopped := make(chan struct{})
+
+	go func() {
+		s.wait()
+		close(stopped)
+	}()
+
+	tcp, err := net.Dial("tcp", rconAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tcp.Close()
+
+	_ = tcp.SetDeadline(time.Now().Add(awaitTimeout))
+
+	if _, err := tcp.Write(append(rconPacket(1, 3, "secret"), rconPacket(2, 2, "stop")...)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the auth response, then the answer to stop before rcon closes
+	received, err := ioutil.ReadAll(tcp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(received), "Stopping the server") {
+		t.Fatalf("stop was not answered, received %q", received)
+	}
+
+	// a console stop racing the rcon one does nothing
+	s.Kill()
+
+	select {
+	case <-stopped:
+	case <-time.After(awaitTimeout):
+		t.Fatal("the server did not stop")
+	}
+}
+
+// rconPacket encodes a source rcon packet, the integers are little endian
+func rconPacket(id int32, kind int32, body string) []byte {
+	packet := make([]byte, 4+4+4+len(body)+2)
+
+	binary.LittleEndian.PutUint32(packet[0:], uint32(len(packet)-4))
+	binary.LittleEndian.PutUint32(packet[4:], uint32(id))
+	binary.LittleEndian.PutUint32(packet[8:], uint32(kind))
+	copy(packet[12:], body)
+
+	return packet
+}
+
+// handshake encodes a handshake frame moving to the state
+func handshake(address string, state base.PacketState) []byte {
+	host, _, _ := net.SplitHostPort(address)
+
+	return handshakeHost(address, host, state)
+}
+
+// handshakeHost encodes a handshake frame claiming to connect to the host
+func handshakeHost(address string, host string, state base.PacketState) []byte {
+	_, port, _ := net.SplitHostPort(address)
+	number, _ := strconv.Atoi(port)
+
+	packet := conn.NewBuffer()
+	packet.PushVrI(0x00)
+	packet.PushVrI(int32(data.CurrentProtocol.Protocol()))
+	packet.PushTxt(host)
+	packet.PushI16(int16(number))
+	packet.PushVrI(int32(state))
+
+	frame := conn.NewBuffer()
+	frame.PushVrI(int32(packet.Len()))
+	frame.PushUAS(packet.UAS(), false)
+
+	return frame.UAS()
+}
+
+func TestServer_LoginQuery(t *testing.T) {
+	s, address := startServer(t, nil)
+
+	handler := s.Watcher().SubAs(func(event apis_event.PlayerLoginEvent) {
+		if !strings.HasPrefix(event.Profile.Name, "query") {
+			return
+		}
+
+		event.Query("test:mods", []byte("list"), 2*time.Second, func(response apis_event.LoginResponse) {
+			if !response.Understood || string(response.Data) != "vanilla" {
+				event.Disconnect("missing mods")
+			}
+		})
+	})
+
+	t.Cleanup(handler.UnSub)
+
+	modded := bots.NewBot("querymodded", data.MC1_15_2)
+	modded.Answer = func(channel string, data []byte) ([]byte, bool) {
+		return []byte("vanilla"), channel == "test:mods" && string(data) == "list"
+	}
+
+	if err := modded.Join(address); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = modded.Close()
+
+	for _, version := range []data.MinecraftVersion{data.MC1_13_2, data.MC1_15_2} {
+		bot := bots.NewBot("queryplain", version)
+
+		if err := bot.Join(address); err == nil || !strings.Contains(err.Error(), "missing mods") {
+			t.Fatalf("%v: joined without answering, %v", version, err)
+		}
+	}
+}
+
+func TestServer_Channels(t *testing.T) {
+	s, address := startServer(t, nil)
+
+	channels := s.Channels()
+	channels.Register("test:echo", nil, func(player ents.Player, message chns.Message) {
+		if channels.Listens(player, "test:echo") {
+			channels.Send(player, &chns.Raw{Channel: "test:echo", Data: message.(*chns.Raw).Data})
+		}
+	})
+
+	t.Cleanup(func() {
+		channels.Unregister("test:echo")
+	})
+
+	for _, version := range []data.MinecraftVersion{data.MC1_13_2, data.MC1_15_2} {
+		bot := bots.NewBot("channels"+strconv.Itoa(version.Protocol()), version)
+		if err := bot.Join(address); err != nil {
+			t.Fatalf("%v: %v", version, err)
+		}
+
+		bot.SendPacket(&server_packet.PacketIPluginMessage{Message: &plugin.Register{Channels: []string{"test:echo"}}})
+		bot.SendPacket(&server_packet.PacketIPluginMessage{Message: &chns.Raw{Channel: "test:echo", Data: []byte("ping")}})
+
+		announced, echoed := false, false
+		timeout := time.After(awaitTimeout)
+
+		for !announced || !echoed {
+			select {
+			case packet, ok := <-bot.Packets():
+				if !ok {
+					t.Fatalf("%v: disconnected, %v", version, bot.Err())
+				}
+
+				message, ok := packet.(*client.PacketOPluginMessage)
+				if !ok {
+					continue
+				}
+
+				switch message := message.Message.(type) {
+				case *plugin.Register:
+					announced = len(message.Channels) == 1 && message.Channels[0] == "test:echo"
+				case *chns.Raw:
+					echoed = message.Channel == "test:echo" && string(message.Data) == "ping"
+				}
+			case <-timeout:
+				t.Fatalf("%v: announced %v, echoed %v", version, announced, echoed)
+			}
+		}
+
+		_ = bot.Close()
+	}
+}
+
+// awaitConn polls the connection of the uuid until it satisfies the check
+func awaitConn(s *server, uuid uuid.UUID, check func(conn base.Connection) bool) bool {
+	for deadline := time.Now().Add(awaitTimeout); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if check(s.ConnByUUID(uuid)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// awaitPacket drains the bot's packets until one like expected arrives
+func awaitPacket(bot *bots.Bot, expected base.PacketI) bool {
+	timeout := time.After(awaitTimeout)
+
+	for {
+		select {
+		case packet, ok := <-bot.Packets():
+			if !ok {
+				return false
+			}
+
+			if reflect.TypeOf(packet) == reflect.TypeOf(expected) {
+				return true
+			}
+		case <-timeout:
+			return false
+		}
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
 
 	"github.com/fatih/color"
 
@@ -12,7 +14,18 @@ import (
 func main() {
 	color.NoColor = false
 
-	server := impl.NewServer(mergeWithFlags(&conf.DefaultServerConfig))
+	config := mergeWithFlags(&conf.DefaultServerConfig)
+	if err := config.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	server, err := impl.NewServer(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	server.Load()
 }
 
@@ -29,6 +42,10 @@ func mergeWithFlags(c *conf.ServerConfig) *conf.ServerConfig {
 		conf.DefaultServerConfig.OnlineMode,
 		"the mode which in server will work")
 
+	capture := flag.String("capture",
+		conf.DefaultServerConfig.Network.CaptureFile,
+		"the file every packet is captured to, for debugging")
+
 	flag.Parse()
 
 	if *host != conf.DefaultServerConfig.Network.Host {
@@ -43,5 +60,9 @@ func mergeWithFlags(c *conf.ServerConfig) *conf.ServerConfig {
 		c.OnlineMode = *onlineMode
 	}
 
+	if *capture != conf.DefaultServerConfig.Network.CaptureFile {
+		c.Network.CaptureFile = *capture
+	}
+
 	return c
 }